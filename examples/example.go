@@ -17,7 +17,7 @@ type User struct {
 	Name  string ` + "`bind:\"header,required\"`" + `
 	Email string ` + "`bind:\"query\"`" + `
 	Age   int    ` + "`validate:\"min=18,max=120\"`" + `
-	ID    string ` + "`bind:\"path,required\" validate:\"max=10\"`" + `
+	ID    string ` + "`bind:\"path,required\" validate:\"maxlen=10\"`" + `
 }
 `
 
@@ -37,18 +37,27 @@ type User struct {
 		}
 		if tag.Validate != nil {
 			if tag.Validate.Min != nil {
-				fmt.Printf("  Validate Min: %d\n", *tag.Validate.Min)
+				fmt.Printf("  Validate Min: %s\n", tag.Validate.Min.Raw)
 			}
 			if tag.Validate.Max != nil {
-				fmt.Printf("  Validate Max: %d\n", *tag.Validate.Max)
+				fmt.Printf("  Validate Max: %s\n", tag.Validate.Max.Raw)
+			}
+			if tag.Validate.MaxLen != nil {
+				fmt.Printf("  Validate MaxLen: %d\n", *tag.Validate.MaxLen)
 			}
 		}
 		fmt.Println()
 	}
 
 	// Generate the bind and validate functions
-	bindCode, _ := generator.GenerateBindFunction(structInfo)
-	validateCode, _ := generator.GenerateValidateFunction(structInfo)
+	bindCode, _, err := generator.GenerateBindFunction(structInfo, generator.Options{})
+	if err != nil {
+		log.Fatalf("Failed to generate bind function: %v", err)
+	}
+	validateCode, _, err := generator.GenerateValidateFunction(structInfo, generator.Options{})
+	if err != nil {
+		log.Fatalf("Failed to generate validate function: %v", err)
+	}
 
 	// Print the generated bind function
 	fmt.Println("Generated bind function:")
@@ -57,4 +66,4 @@ type User struct {
 	// Print the generated validate function
 	fmt.Println("Generated validate function:")
 	fmt.Println(validateCode)
-}
\ No newline at end of file
+}
@@ -4,5 +4,5 @@ type User struct {
 	Name  string `bind:"header,required"`
 	Email string `bind:"query"`
 	Age   int    `validate:"min=18,max=120"`
-	ID    string `bind:"path,required" validate:"max=10"`
+	ID    string `bind:"path,required" validate:"maxlen=10"`
 }
\ No newline at end of file
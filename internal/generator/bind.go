@@ -0,0 +1,434 @@
+package generator
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/pangobit/go-wrangler/internal/parse"
+)
+
+// defaultMaxBodyBytes is the request body size limit used when no body field sets maxbytes.
+const defaultMaxBodyBytes = 1 << 20
+
+// defaultMaxFormMemory is the number of bytes of a multipart form kept in memory, matching net/http.
+const defaultMaxFormMemory = 32 << 20
+
+// GenerateBindFunction generates Go code for a bind function that takes an http.Request and path params,
+// binds them to the struct fields according to the bind tags.
+// The generated function reports failures as wrangler.Errors, collecting every failing field unless
+// opts.FailFast is set.
+func GenerateBindFunction(structInfo parse.StructInfo, opts Options) (string, []string, error) {
+	w := &codeWriter{failFast: opts.FailFast}
+	w.addImports("net/http")
+
+	needsForm := false
+	for _, tag := range structInfo.Tags {
+		if tag.Bind == nil {
+			continue
+		}
+		if tag.Bind.Type == "form" || tag.Bind.Type == "file" {
+			needsForm = true
+		}
+		if tag.Bind.Type == "file" && tag.FieldType != "*multipart.FileHeader" && tag.FieldType != "[]*multipart.FileHeader" {
+			return "", nil, fmt.Errorf("%s.%s: file fields must be *multipart.FileHeader or []*multipart.FileHeader, not %s", structInfo.Name, tag.FieldName, tag.FieldType)
+		}
+	}
+
+	body, err := collectBodyFields(structInfo)
+	if err != nil {
+		return "", nil, err
+	}
+	if body != nil && needsForm {
+		return "", nil, fmt.Errorf("%s: body fields can't be combined with form or file fields", structInfo.Name)
+	}
+
+	// Function signature
+	w.linef(0, "func Bind%s(r *http.Request, s *%s) error {", structInfo.Name, structInfo.Name)
+	w.writeErrsDecl()
+
+	if body != nil {
+		if err := w.writeBodyBinding(structInfo.Name, body); err != nil {
+			return "", nil, err
+		}
+	}
+	if needsForm {
+		w.writeFormParsing()
+	}
+	if slices.ContainsFunc(structInfo.Tags, func(tag parse.TagInfo) bool { return tag.Bind != nil && tag.Bind.Type == "query" }) {
+		w.linef(1, "query := r.URL.Query()")
+	}
+
+	// Bind logic
+	for _, tag := range structInfo.Tags {
+		if tag.Bind == nil {
+			continue
+		}
+		if tag.Bind.Layout != "" && baseType(strings.TrimPrefix(underlyingType(tag), "[]")) != "time.Time" {
+			return "", nil, fmt.Errorf("%s.%s: layout only applies to time.Time fields", structInfo.Name, tag.FieldName)
+		}
+		if tag.TextUnmarshaler {
+			w.addImports(tag.TypeImports...)
+		}
+		var err error
+		switch {
+		case tag.Bind.Type == "body":
+			// Bound by writeBodyBinding
+		case tag.Bind.Type == "file":
+			err = w.writeFileBinding(tag)
+		case tag.Bind.Type == "cookie":
+			err = w.writeCookieBinding(tag)
+		case isSliceType(underlyingType(tag)):
+			err = w.writeValuesBinding(tag)
+		default:
+			err = w.writeValueBinding(tag)
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("%s.%s: %w", structInfo.Name, tag.FieldName, err)
+		}
+	}
+
+	w.writeReturn()
+	w.linef(0, "}")
+
+	return w.String(), w.imports, nil
+}
+
+// paramName returns the request parameter name a field is bound from: the name given in the
+// bind tag, falling back to the json tag for body fields and to the Go field name otherwise.
+func paramName(tag parse.TagInfo) string {
+	if tag.Bind.Name != "" {
+		return tag.Bind.Name
+	}
+	if tag.Bind.Type == "body" && tag.JSONName != "" {
+		return tag.JSONName
+	}
+	return tag.FieldName
+}
+
+// writeValueBinding writes code binding a single query, header, path or form value to a scalar field,
+// or a pointer to one. An absent or empty value is missing: it leaves the field untouched (a pointer
+// stays nil) unless the field is required, and only values that are present are parsed.
+func (w *codeWriter) writeValueBinding(tag parse.TagInfo) error {
+	ref := bindRef(tag)
+	name := ref.param
+	var valueExpr string
+	switch tag.Bind.Type {
+	case "query":
+		valueExpr = fmt.Sprintf("query.Get(%q)", name)
+	case "header":
+		valueExpr = fmt.Sprintf("r.Header.Get(%q)", name)
+	case "path":
+		valueExpr = fmt.Sprintf("r.PathValue(%q)", name)
+	case "form":
+		valueExpr = fmt.Sprintf("r.PostForm.Get(%q)", name)
+	}
+	w.linef(1, "if v := %s; v != \"\" {", valueExpr)
+	if err := w.writeConversion(2, tag, baseType(underlyingType(tag)), "v", ref, w.fieldAssign(tag.FieldName, tag.FieldType)); err != nil {
+		return err
+	}
+	return w.writeMissingElse(tag)
+}
+
+// writeMissingElse closes the block binding a present value, adding an else branch for a missing
+// value: required fields return an error, and fields with a default are set to it.
+func (w *codeWriter) writeMissingElse(tag parse.TagInfo) error {
+	setDefault, err := w.defaultAssign(tag)
+	if err != nil {
+		return err
+	}
+	if tag.Bind.Required {
+		w.linef(1, "} else {")
+		w.writeFail(2, bindRef(tag), "required", paramName(tag)+" is required")
+	} else if setDefault != nil {
+		w.linef(1, "} else {")
+		setDefault(2)
+	}
+	w.linef(1, "}")
+	return nil
+}
+
+// defaultAssign returns a function writing code that sets a field to the default of its bind tag,
+// or nil if it has none. The default is checked against the field type, so that a default that
+// doesn't fit the type fails generation rather than the request.
+func (w *codeWriter) defaultAssign(tag parse.TagInfo) (func(depth int), error) {
+	if tag.Bind.Default == nil {
+		return nil, nil
+	}
+	typ := underlyingType(tag)
+	if baseType(strings.TrimPrefix(typ, "[]")) == "time.Time" {
+		return nil, fmt.Errorf("time.Time fields can't have a default")
+	}
+	if tag.TextUnmarshaler {
+		return nil, fmt.Errorf("fields bound with UnmarshalText can't have a default")
+	}
+	if isSliceType(typ) {
+		var items []string
+		for _, item := range parse.SplitList(*tag.Bind.Default) {
+			lit, err := literal(strings.TrimPrefix(typ, "[]"), item)
+			if err != nil {
+				return nil, fmt.Errorf("default %w", err)
+			}
+			items = append(items, lit)
+		}
+		value := fmt.Sprintf("%s{%s}", tag.FieldType, strings.Join(items, ", "))
+		return func(depth int) {
+			w.linef(depth, "s.%s = %s", tag.FieldName, value)
+		}, nil
+	}
+	lit, err := literal(baseType(typ), parse.Unescape(*tag.Bind.Default))
+	if err != nil {
+		return nil, fmt.Errorf("default %w", err)
+	}
+	assign := w.fieldAssign(tag.FieldName, tag.FieldType)
+	return func(depth int) {
+		assign(depth, lit)
+	}, nil
+}
+
+// writeFormParsing writes code that parses the request form once, before any form or file field is bound.
+// Multipart bodies are parsed with ParseMultipartForm so that both values and files are available.
+// A form that can't be parsed stops binding.
+func (w *codeWriter) writeFormParsing() {
+	w.addImports("fmt", "mime")
+	ref := fieldRef{source: "form"}
+	w.linef(1, "if mediaType, _, _ := mime.ParseMediaType(r.Header.Get(\"Content-Type\")); mediaType == \"multipart/form-data\" {")
+	w.linef(2, "if err := r.ParseMultipartForm(%d); err != nil {", defaultMaxFormMemory)
+	w.writeAbort(3, ref, "form", `fmt.Sprintf("invalid multipart form: %v", err)`, "err")
+	w.linef(2, "}")
+	w.linef(1, "} else if err := r.ParseForm(); err != nil {")
+	w.writeAbort(2, ref, "form", `fmt.Sprintf("invalid form: %v", err)`, "err")
+	w.linef(1, "}")
+}
+
+// writeValuesBinding writes code binding every value of a repeated query, header or form parameter
+// to a slice field. With explode=false each value is also split into a comma-separated list.
+func (w *codeWriter) writeValuesBinding(tag parse.TagInfo) error {
+	ref := bindRef(tag)
+	name := ref.param
+	var valuesExpr string
+	switch tag.Bind.Type {
+	case "query":
+		valuesExpr = fmt.Sprintf("query[%q]", name)
+	case "header":
+		valuesExpr = fmt.Sprintf("r.Header.Values(%q)", name)
+	case "form":
+		valuesExpr = fmt.Sprintf("r.PostForm[%q]", name)
+	default:
+		return fmt.Errorf("slice fields can't be bound from %s", tag.Bind.Type)
+	}
+
+	elemType := strings.TrimPrefix(underlyingType(tag), "[]")
+	w.linef(1, "if vals := %s; len(vals) > 0 {", valuesExpr)
+	if valueType(tag) == "string" && !tag.Bind.CommaSeparated {
+		w.linef(2, "s.%s = vals", tag.FieldName)
+	} else {
+		w.linef(2, "items := make(%s, 0, len(vals))", tag.FieldType)
+		w.linef(2, "for _, v := range vals {")
+		depth := 3
+		if tag.Bind.CommaSeparated {
+			w.addImports("strings")
+			w.linef(3, "for _, v := range strings.Split(v, \",\") {")
+			w.linef(4, "v = strings.TrimSpace(v)")
+			depth = 4
+		}
+		appendItem := func(depth int, value string) {
+			w.linef(depth, "items = append(items, %s)", value)
+		}
+		if err := w.writeConversion(depth, tag, elemType, "v", ref, appendItem); err != nil {
+			return err
+		}
+		if tag.Bind.CommaSeparated {
+			w.linef(3, "}")
+		}
+		w.linef(2, "}")
+		w.linef(2, "s.%s = items", tag.FieldName)
+	}
+	return w.writeMissingElse(tag)
+}
+
+// writeCookieBinding writes code binding a request cookie to a field. A *http.Cookie field receives
+// the cookie itself, any other field its value. http.ErrNoCookie means the cookie is missing, as
+// does an empty value for fields bound to the cookie value.
+func (w *codeWriter) writeCookieBinding(tag parse.TagInfo) error {
+	ref := bindRef(tag)
+	name := ref.param
+	if tag.FieldType == "*http.Cookie" {
+		if tag.Bind.Default != nil {
+			return fmt.Errorf("*http.Cookie fields can't have a default")
+		}
+		w.linef(1, "if c, err := r.Cookie(%q); err == nil {", name)
+		w.linef(2, "s.%s = c", tag.FieldName)
+	} else {
+		w.linef(1, "if c, err := r.Cookie(%q); err == nil && c.Value != \"\" {", name)
+		if err := w.writeConversion(2, tag, baseType(underlyingType(tag)), "c.Value", ref, w.fieldAssign(tag.FieldName, tag.FieldType)); err != nil {
+			return err
+		}
+	}
+	return w.writeMissingElse(tag)
+}
+
+// writeFileBinding writes code binding uploaded files to a *multipart.FileHeader or []*multipart.FileHeader
+// field, enforcing the per-file size limit and accepted media types of the tag.
+// Media types are checked against the Content-Type sent with each file part.
+func (w *codeWriter) writeFileBinding(tag parse.TagInfo) error {
+	ref := bindRef(tag)
+	name := ref.param
+	files := fmt.Sprintf("r.MultipartForm.File[%q]", name)
+	w.linef(1, "if r.MultipartForm != nil && len(%s) > 0 {", files)
+	if tag.Bind.MaxBytes > 0 || len(tag.Bind.Accept) > 0 {
+		w.linef(2, "for _, fh := range %s {", files)
+		if tag.Bind.MaxBytes > 0 {
+			w.linef(3, "if fh.Size > %d {", tag.Bind.MaxBytes)
+			w.addImports("fmt")
+			message := fmt.Sprintf("%s: file %%q must not exceed %d bytes", name, tag.Bind.MaxBytes)
+			w.writeFailExpr(4, ref, "maxbytes", "fmt.Sprintf("+strconv.Quote(message)+", fh.Filename)", "")
+			w.linef(3, "}")
+		}
+		if len(tag.Bind.Accept) > 0 {
+			var conds []string
+			for _, mediaType := range tag.Bind.Accept {
+				if prefix, ok := strings.CutSuffix(mediaType, "/*"); ok {
+					conds = append(conds, fmt.Sprintf("strings.HasPrefix(mediaType, %q)", prefix+"/"))
+					w.addImports("strings")
+				} else {
+					conds = append(conds, fmt.Sprintf("mediaType == %q", mediaType))
+				}
+			}
+			w.linef(3, "if mediaType, _, _ := mime.ParseMediaType(fh.Header.Get(\"Content-Type\")); !(%s) {", strings.Join(conds, " || "))
+			w.addImports("fmt")
+			message := fmt.Sprintf("%s: file %%q must be of type %s", name, strings.Join(tag.Bind.Accept, ", "))
+			w.writeFailExpr(4, ref, "accept", "fmt.Sprintf("+strconv.Quote(message)+", fh.Filename)", "")
+			w.linef(3, "}")
+		}
+		w.linef(2, "}")
+	}
+	if isSliceType(tag.FieldType) {
+		w.linef(2, "s.%s = %s", tag.FieldName, files)
+	} else {
+		w.linef(2, "s.%s = %s[0]", tag.FieldName, files)
+	}
+	return w.writeMissingElse(tag)
+}
+
+// bodyBinding describes how the JSON request body of a struct is decoded.
+// Either whole is set, and the entire body is decoded into that field, or
+// fields lists the fields decoded from individual keys of a JSON object.
+type bodyBinding struct {
+	whole        *parse.TagInfo
+	fields       []parse.TagInfo
+	allowUnknown bool
+	maxBytes     int64
+}
+
+// collectBodyFields gathers the body-bound fields of a struct, returning nil if there are none.
+// The body can only be read once, so whole-body and per-key fields can't be mixed, and all
+// body fields must agree on the size limit. Each key can only be bound to one field.
+func collectBodyFields(structInfo parse.StructInfo) (*bodyBinding, error) {
+	var body *bodyBinding
+	for _, tag := range structInfo.Tags {
+		if tag.Bind == nil || tag.Bind.Type != "body" {
+			continue
+		}
+		if body == nil {
+			body = &bodyBinding{maxBytes: defaultMaxBodyBytes}
+		}
+		if tag.Bind.Whole {
+			if body.whole != nil || len(body.fields) > 0 {
+				return nil, fmt.Errorf("%s.%s: a whole body field can't be combined with other body fields", structInfo.Name, tag.FieldName)
+			}
+			body.whole = &tag
+		} else {
+			if body.whole != nil {
+				return nil, fmt.Errorf("%s.%s: a whole body field can't be combined with other body fields", structInfo.Name, tag.FieldName)
+			}
+			if paramName(tag) == "-" {
+				return nil, fmt.Errorf("%s.%s: field is bound from the body but its json tag is \"-\"", structInfo.Name, tag.FieldName)
+			}
+			for _, other := range body.fields {
+				if paramName(other) == paramName(tag) {
+					return nil, fmt.Errorf("%s.%s: body key %q is also bound to %s", structInfo.Name, tag.FieldName, paramName(tag), other.FieldName)
+				}
+			}
+			body.fields = append(body.fields, tag)
+		}
+		if tag.Bind.AllowUnknown {
+			body.allowUnknown = true
+		}
+		if tag.Bind.MaxBytes != 0 {
+			if body.maxBytes != defaultMaxBodyBytes && body.maxBytes != tag.Bind.MaxBytes {
+				return nil, fmt.Errorf("%s.%s: conflicting maxbytes for body fields", structInfo.Name, tag.FieldName)
+			}
+			body.maxBytes = tag.Bind.MaxBytes
+		}
+	}
+	return body, nil
+}
+
+// writeBodyBinding writes code that streams the JSON request body through encoding/json.
+// Per-key fields are decoded into pointers of an anonymous struct so that absent keys can be
+// told apart from zero values before they are copied into s. A body that can't be decoded
+// stops binding. Errors name the body fields of the struct by structName.Field.
+func (w *codeWriter) writeBodyBinding(structName string, body *bodyBinding) error {
+	w.addImports("encoding/json", "errors", "fmt", "io", "mime", "strings")
+
+	target := "&body"
+	if body.whole != nil {
+		target = "&s." + body.whole.FieldName
+	} else {
+		w.linef(1, "var body struct {")
+		for _, tag := range body.fields {
+			w.addImports(tag.TypeImports...)
+			w.linef(2, "%s *%s `json:%q`", identName(tag.FieldName), tag.FieldType, paramName(tag))
+		}
+		w.linef(1, "}")
+	}
+
+	var writeMissing func(depth int)
+	if body.whole != nil && body.whole.Bind.Required {
+		writeMissing = func(depth int) {
+			ref := fieldRef{field: body.whole.FieldName, source: "body"}
+			w.writeFail(depth, ref, "required", body.whole.FieldName+" is required")
+		}
+	}
+
+	ref := fieldRef{source: "body"}
+	w.linef(1, "if r.Body != nil && r.Body != http.NoBody {")
+	w.linef(2, "if mediaType, _, err := mime.ParseMediaType(r.Header.Get(\"Content-Type\")); err != nil || (mediaType != \"application/json\" && !strings.HasSuffix(mediaType, \"+json\")) {")
+	w.writeAbort(3, ref, "content_type", `fmt.Sprintf("unsupported content type %q: expected application/json", r.Header.Get("Content-Type"))`, "")
+	w.linef(2, "}")
+	w.linef(2, "dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, %d))", body.maxBytes)
+	if !body.allowUnknown {
+		w.linef(2, "dec.DisallowUnknownFields()")
+	}
+	if writeMissing != nil {
+		w.linef(2, "if err := dec.Decode(%s); err == io.EOF {", target)
+		writeMissing(3)
+		w.linef(2, "} else if err != nil {")
+	} else {
+		w.linef(2, "if err := dec.Decode(%s); err != nil && err != io.EOF {", target)
+	}
+	w.linef(3, "var maxErr *http.MaxBytesError")
+	w.linef(3, "if errors.As(err, &maxErr) {")
+	w.writeAbort(4, ref, "maxbytes", `fmt.Sprintf("request body must not exceed %d bytes", maxErr.Limit)`, "err")
+	w.linef(3, "}")
+	w.writeAbort(3, ref, "json", `fmt.Sprintf("invalid JSON request body: %v", err)`, "err")
+	w.linef(2, "} else if dec.More() {")
+	w.writeAbort(3, ref, "json", strconv.Quote("request body must contain a single JSON value"), "")
+	w.linef(2, "}")
+	if writeMissing != nil {
+		w.linef(1, "} else {")
+		writeMissing(2)
+	}
+	w.linef(1, "}")
+
+	for _, tag := range body.fields {
+		w.linef(1, "if body.%s != nil {", identName(tag.FieldName))
+		w.linef(2, "s.%s = *body.%s", tag.FieldName, identName(tag.FieldName))
+		if err := w.writeMissingElse(tag); err != nil {
+			return fmt.Errorf("%s.%s: %w", structName, tag.FieldName, err)
+		}
+	}
+	return nil
+}
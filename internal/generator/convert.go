@@ -0,0 +1,326 @@
+package generator
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pangobit/go-wrangler/internal/parse"
+)
+
+// wranglerImport is the runtime package imported by generated code for its error types.
+const wranglerImport = "github.com/pangobit/go-wrangler/wrangler"
+
+// codeWriter accumulates generated code along with the imports that code needs.
+// failFast is set when the generated functions return their first failure instead of collecting them all.
+type codeWriter struct {
+	strings.Builder
+	imports  []string
+	failFast bool
+}
+
+// addImports records import paths needed by the generated code, ignoring ones already recorded.
+func (w *codeWriter) addImports(paths ...string) {
+	for _, path := range paths {
+		if !slices.Contains(w.imports, path) {
+			w.imports = append(w.imports, path)
+		}
+	}
+}
+
+// linef writes a formatted line indented by depth tabs.
+func (w *codeWriter) linef(depth int, format string, args ...any) {
+	w.WriteString(strings.Repeat("\t", depth))
+	w.WriteString(fmt.Sprintf(format, args...))
+	w.WriteString("\n")
+}
+
+// identName returns a Go identifier for a field, joining the selectors of fields of nested structs,
+// such as Filter.Status.
+func identName(fieldName string) string {
+	return strings.ReplaceAll(fieldName, ".", "_")
+}
+
+// fieldRef identifies the field, and where it is bound from, in errors reported by generated code.
+type fieldRef struct {
+	field  string
+	param  string
+	source string
+}
+
+// bindRef returns the fieldRef of a bound field.
+func bindRef(tag parse.TagInfo) fieldRef {
+	return fieldRef{field: tag.FieldName, param: paramName(tag), source: tag.Bind.Type}
+}
+
+// fieldError returns a Go expression building a *wrangler.FieldError for ref. message and cause
+// are Go expressions for the message and the underlying error, cause being empty if there is none.
+func (ref fieldRef) fieldError(rule, message, cause string) string {
+	var fields []string
+	for _, f := range []struct{ name, value string }{
+		{"Field", strconv.Quote(ref.field)},
+		{"Param", strconv.Quote(ref.param)},
+		{"Source", strconv.Quote(ref.source)},
+		{"Rule", strconv.Quote(rule)},
+		{"Message", message},
+		{"Err", cause},
+	} {
+		if f.value != "" && f.value != `""` {
+			fields = append(fields, f.name+": "+f.value)
+		}
+	}
+	return "&wrangler.FieldError{" + strings.Join(fields, ", ") + "}"
+}
+
+// writeFail writes code reporting that a field failed rule with a constant message. The failure
+// is appended to errs, or returned straight away in fail-fast mode.
+func (w *codeWriter) writeFail(depth int, ref fieldRef, rule, message string) {
+	w.writeFailExpr(depth, ref, rule, strconv.Quote(message), "")
+}
+
+// writeFailExpr is like writeFail, with the message given as a Go expression evaluated at runtime,
+// and cause, if not empty, the underlying error.
+func (w *codeWriter) writeFailExpr(depth int, ref fieldRef, rule, message, cause string) {
+	w.addImports(wranglerImport)
+	if w.failFast {
+		w.linef(depth, "return wrangler.Errors{%s}", ref.fieldError(rule, message, cause))
+	} else {
+		w.linef(depth, "errs = append(errs, %s)", ref.fieldError(rule, message, cause))
+	}
+}
+
+// writeAbort writes code returning a failure wrapping cause in either mode, for requests that
+// can't be bound any further, such as a body that can't be read.
+func (w *codeWriter) writeAbort(depth int, ref fieldRef, rule, message, cause string) {
+	w.addImports(wranglerImport)
+	w.linef(depth, "return wrangler.Errors{%s}", ref.fieldError(rule, message, cause))
+}
+
+// writeErrsDecl writes the declaration of the errs list failures are collected in, if any.
+func (w *codeWriter) writeErrsDecl() {
+	if !w.failFast {
+		w.addImports(wranglerImport)
+		w.linef(1, "var errs wrangler.Errors")
+	}
+}
+
+// writeReturn writes the final return of a generated function, reporting the collected failures.
+func (w *codeWriter) writeReturn() {
+	if w.failFast {
+		w.linef(1, "return nil")
+	} else {
+		w.linef(1, "return errs.Err()")
+	}
+}
+
+// scalarType describes how a request string is parsed into a Go scalar type with strconv.
+// parse is the strconv call, with %s for the string being parsed, and convert turns the
+// parsed val into the field type. invalid is the error message for values that don't parse.
+// ranged is set when parse reports values that overflow the type with strconv.ErrRange.
+// bits is the bit size passed to parse, used to check default values at generation time.
+// finite is set for floats, whose NaN and infinities parse but are invalid, as they escape bounds.
+type scalarType struct {
+	parse   string
+	convert string
+	invalid string
+	ranged  bool
+	bits    int
+	finite  bool
+}
+
+var scalarTypes = map[string]scalarType{
+	"bool":    {"strconv.ParseBool(%s)", "val", "must be a boolean", false, 0, false},
+	"int":     {"strconv.ParseInt(%s, 10, 0)", "int(val)", "must be a valid integer", true, 0, false},
+	"int8":    {"strconv.ParseInt(%s, 10, 8)", "int8(val)", "must be a valid integer", true, 8, false},
+	"int16":   {"strconv.ParseInt(%s, 10, 16)", "int16(val)", "must be a valid integer", true, 16, false},
+	"int32":   {"strconv.ParseInt(%s, 10, 32)", "int32(val)", "must be a valid integer", true, 32, false},
+	"rune":    {"strconv.ParseInt(%s, 10, 32)", "rune(val)", "must be a valid integer", true, 32, false},
+	"int64":   {"strconv.ParseInt(%s, 10, 64)", "val", "must be a valid integer", true, 64, false},
+	"uint":    {"strconv.ParseUint(%s, 10, 0)", "uint(val)", "must be a valid unsigned integer", true, 0, false},
+	"uint8":   {"strconv.ParseUint(%s, 10, 8)", "uint8(val)", "must be a valid unsigned integer", true, 8, false},
+	"byte":    {"strconv.ParseUint(%s, 10, 8)", "byte(val)", "must be a valid unsigned integer", true, 8, false},
+	"uint16":  {"strconv.ParseUint(%s, 10, 16)", "uint16(val)", "must be a valid unsigned integer", true, 16, false},
+	"uint32":  {"strconv.ParseUint(%s, 10, 32)", "uint32(val)", "must be a valid unsigned integer", true, 32, false},
+	"uint64":  {"strconv.ParseUint(%s, 10, 64)", "val", "must be a valid unsigned integer", true, 64, false},
+	"float32": {"strconv.ParseFloat(%s, 32)", "float32(val)", "must be a valid number", true, 32, true},
+	"float64": {"strconv.ParseFloat(%s, 64)", "val", "must be a valid number", true, 64, true},
+}
+
+// literal checks value as a constant of type typ at generation time, with the same parsing the
+// generated code applies to request values, and returns it as a Go literal. Durations are written
+// as their number of nanoseconds, so the time package doesn't have to be imported.
+func literal(typ, value string) (string, error) {
+	switch typ {
+	case "string":
+		return strconv.Quote(value), nil
+	case "[]byte":
+		return "[]byte(" + strconv.Quote(value) + ")", nil
+	case "time.Duration":
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return "", fmt.Errorf("%q must be a duration such as 1h30m or 500ms", value)
+		}
+		return strconv.FormatInt(int64(d), 10), nil
+	}
+
+	scalar, ok := scalarTypes[typ]
+	if !ok {
+		return "", fmt.Errorf("unsupported field type %q", typ)
+	}
+	// Sizes of 0 mean int and uint, which are checked against 64 bits like the target platform
+	bits := scalar.bits
+	if bits == 0 {
+		bits = 64
+	}
+	var lit string
+	var err error
+	switch {
+	case typ == "bool":
+		var b bool
+		b, err = strconv.ParseBool(value)
+		lit = strconv.FormatBool(b)
+	case strings.HasPrefix(scalar.parse, "strconv.ParseInt"):
+		var i int64
+		i, err = strconv.ParseInt(value, 10, bits)
+		lit = strconv.FormatInt(i, 10)
+	case strings.HasPrefix(scalar.parse, "strconv.ParseUint"):
+		var u uint64
+		u, err = strconv.ParseUint(value, 10, bits)
+		lit = strconv.FormatUint(u, 10)
+	default:
+		var f float64
+		f, err = strconv.ParseFloat(value, bits)
+		if err == nil && scalar.finite && (math.IsInf(f, 0) || math.IsNaN(f)) {
+			err = strconv.ErrSyntax
+		}
+		lit = strconv.FormatFloat(f, 'g', -1, bits)
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return "", fmt.Errorf("%q is out of range for %s", value, typ)
+	} else if err != nil {
+		return "", fmt.Errorf("%q %s", value, scalar.invalid)
+	}
+	return lit, nil
+}
+
+// assignFunc writes code at the given depth storing a converted value expression.
+type assignFunc func(depth int, value string)
+
+// fieldAssign returns an assignFunc storing values in the field s.<fieldName>. Pointer fields are
+// allocated first, so they stay nil unless a value is bound.
+func (w *codeWriter) fieldAssign(fieldName, fieldType string) assignFunc {
+	return func(depth int, value string) {
+		if elemType, ok := strings.CutPrefix(fieldType, "*"); ok {
+			w.linef(depth, "s.%s = new(%s)", fieldName, elemType)
+			w.linef(depth, "*s.%s = %s", fieldName, value)
+		} else {
+			w.linef(depth, "s.%s = %s", fieldName, value)
+		}
+	}
+}
+
+// writeConversion writes code converting the string expression raw to typ, the underlying type of
+// the value bound to the field of tag, and storing it with assign. Values that don't parse or don't
+// fit in typ fail with an error naming the request parameter, and values of named types are
+// converted to them once parsed. Times are parsed in the layout of the tag, and other types
+// implementing encoding.TextUnmarshaler by their UnmarshalText method.
+func (w *codeWriter) writeConversion(depth int, tag parse.TagInfo, typ, raw string, ref fieldRef, assign assignFunc) error {
+	elem := valueType(tag)
+	switch {
+	case typ == "time.Time":
+		return w.writeTimeConversion(depth, tag.Bind.Layout, raw, ref, assign)
+	case typ == "time.Duration":
+		w.writeDurationConversion(depth, raw, ref, assign)
+		return nil
+	case tag.TextUnmarshaler:
+		w.linef(depth, "var val %s", elem)
+		w.linef(depth, "if err := val.UnmarshalText([]byte(%s)); err != nil {", raw)
+		w.writeFailExpr(depth+1, ref, "type", strconv.Quote(ref.param+" is invalid: ")+" + err.Error()", "err")
+		w.linef(depth, "} else {")
+		assign(depth+1, "val")
+		w.linef(depth, "}")
+		return nil
+	}
+
+	if elem != typ {
+		assignValue := assign
+		assign = func(depth int, value string) {
+			assignValue(depth, elem+"("+value+")")
+		}
+	}
+	switch typ {
+	case "string":
+		assign(depth, raw)
+		return nil
+	case "[]byte":
+		assign(depth, "[]byte("+raw+")")
+		return nil
+	}
+
+	scalar, ok := scalarTypes[typ]
+	if !ok {
+		return fmt.Errorf("unsupported field type %q", typ)
+	}
+	w.addImports("strconv")
+	parseExpr := fmt.Sprintf(scalar.parse, raw)
+	invalid := "err != nil"
+	if scalar.finite {
+		w.addImports("math")
+		invalid += " || math.IsNaN(val) || math.IsInf(val, 0)"
+	}
+	if scalar.ranged {
+		w.addImports("errors")
+		w.linef(depth, "if val, err := %s; errors.Is(err, strconv.ErrRange) {", parseExpr)
+		w.writeFail(depth+1, ref, "range", fmt.Sprintf("%s is out of range for %s", ref.param, typ))
+		w.linef(depth, "} else if %s {", invalid)
+	} else {
+		w.linef(depth, "if val, err := %s; %s {", parseExpr, invalid)
+	}
+	w.writeFail(depth+1, ref, "type", ref.param+" "+scalar.invalid)
+	w.linef(depth, "} else {")
+	assign(depth+1, scalar.convert)
+	w.linef(depth, "}")
+	return nil
+}
+
+// underlyingType returns the underlying type of the field of tag, which decides the code generated
+// for it, or the type as written for tags the parser didn't resolve.
+func underlyingType(tag parse.TagInfo) string {
+	if tag.UnderlyingType != "" {
+		return tag.UnderlyingType
+	}
+	return tag.FieldType
+}
+
+// valueType returns the type of the values bound to the field of tag, or of the field itself
+// without its pointer or slice for tags the parser didn't resolve.
+func valueType(tag parse.TagInfo) string {
+	if tag.ElemType != "" {
+		return tag.ElemType
+	}
+	if tag.FieldType == "[]byte" {
+		return tag.FieldType
+	}
+	return baseType(strings.TrimPrefix(tag.FieldType, "[]"))
+}
+
+// isNumericType reports whether typ is one of the integer or floating point scalar types.
+func isNumericType(typ string) bool {
+	_, ok := scalarTypes[typ]
+	return ok && typ != "bool"
+}
+
+// baseType strips the pointer from a pointer type expression.
+func baseType(typ string) string {
+	return strings.TrimPrefix(typ, "*")
+}
+
+// isSliceType reports whether typ is a slice type expression bound from repeated values.
+// []byte is bound from a single value, like a string.
+func isSliceType(typ string) bool {
+	return strings.HasPrefix(typ, "[]") && typ != "[]byte"
+}
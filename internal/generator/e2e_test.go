@@ -2,6 +2,10 @@ package generator
 
 import (
 	"fmt"
+	"maps"
+	"os"
+	"os/exec"
+	"path/filepath"
 	"strings"
 	"testing"
 
@@ -49,8 +53,14 @@ type User struct {
 			}
 
 			// Generate the bind and validate functions
-			bindCode, _ := GenerateBindFunction(structInfo)
-			validateCode, _ := GenerateValidateFunction(structInfo)
+			bindCode, _, err := GenerateBindFunction(structInfo, Options{})
+			if err != nil {
+				t.Fatalf("GenerateBindFunction() error = %v", err)
+			}
+			validateCode, _, err := GenerateValidateFunction(structInfo, Options{})
+			if err != nil {
+				t.Fatalf("GenerateValidateFunction() error = %v", err)
+			}
 			code := bindCode + validateCode
 
 			// Check that the generated code contains expected elements
@@ -58,7 +68,7 @@ type User struct {
 				fmt.Sprintf("func Bind%s", structInfo.Name),
 				"r *http.Request",
 				fmt.Sprintf("s *%s", structInfo.Name),
-				"return nil",
+				"return errs.Err()",
 			}
 
 			for _, expected := range expectedContains {
@@ -76,7 +86,7 @@ type User struct {
 							t.Errorf("Expected header binding for %s", tag.FieldName)
 						}
 					case "query":
-						if !strings.Contains(code, fmt.Sprintf("r.URL.Query().Get(\"%s\")", tag.FieldName)) {
+						if !strings.Contains(code, fmt.Sprintf("query.Get(\"%s\")", tag.FieldName)) {
 							t.Errorf("Expected query binding for %s", tag.FieldName)
 						}
 					case "path":
@@ -93,12 +103,12 @@ type User struct {
 				if tag.Validate != nil {
 					if tag.FieldType == "int" {
 						if tag.Validate.Min != nil {
-							if !strings.Contains(code, fmt.Sprintf("%s must be at least %d", tag.FieldName, *tag.Validate.Min)) {
+							if !strings.Contains(code, fmt.Sprintf("%s must be at least %s", tag.FieldName, tag.Validate.Min.Raw)) {
 								t.Errorf("Expected min validation for %s", tag.FieldName)
 							}
 						}
 						if tag.Validate.Max != nil {
-							if !strings.Contains(code, fmt.Sprintf("%s must be at most %d", tag.FieldName, *tag.Validate.Max)) {
+							if !strings.Contains(code, fmt.Sprintf("%s must be at most %s", tag.FieldName, tag.Validate.Max.Raw)) {
 								t.Errorf("Expected max validation for %s", tag.FieldName)
 							}
 						}
@@ -107,4 +117,1261 @@ type User struct {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+// runGenerated writes source into a temporary module, generates the bindings for it the same way
+// the CLI does, adds main and returns the output of running the resulting program.
+// The module uses this repository for the wrangler runtime package.
+func runGenerated(t *testing.T, source, main string, opts Options) string {
+	t.Helper()
+	return runGeneratedFiles(t, map[string]string{"types.go": source}, main, opts)
+}
+
+// runGeneratedFiles is like runGenerated, with the source given as files of the module, by path.
+// Files outside its root directory belong to other packages of the module.
+func runGeneratedFiles(t *testing.T, sources map[string]string, main string, opts Options) string {
+	t.Helper()
+	if testing.Short() {
+		t.Skip("skipping e2e test in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain not available")
+	}
+
+	root, err := filepath.Abs("../..")
+	if err != nil {
+		t.Fatalf("Failed to find repository root: %v", err)
+	}
+
+	dir := t.TempDir()
+	files := map[string]string{
+		"go.mod": "module e2e\n\ngo 1.24\n\n" +
+			"require github.com/pangobit/go-wrangler v0.0.0\n\n" +
+			"replace github.com/pangobit/go-wrangler => " + root + "\n",
+	}
+	maps.Copy(files, sources)
+	for name, content := range files {
+		if err := os.MkdirAll(filepath.Dir(filepath.Join(dir, name)), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+
+	structs, pkgName, _, err := parse.ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage failed: %v", err)
+	}
+	code, err := GeneratePackage(structs, pkgName, opts)
+	if err != nil {
+		t.Fatalf("GeneratePackage failed: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "bindings.go"), []byte(code), 0644); err != nil {
+		t.Fatalf("Failed to write bindings.go: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte(main), 0644); err != nil {
+		t.Fatalf("Failed to write main.go: %v", err)
+	}
+
+	cmd := exec.Command(goBin, "run", ".")
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("go run failed: %v\n%s\nGenerated code:\n%s", err, out, code)
+	}
+	return string(out)
+}
+
+func TestE2EMissingValues(t *testing.T) {
+	source := `package main
+
+type Params struct {
+	Page    int     ` + "`bind:\"query\"`" + `
+	Limit   uint8   ` + "`bind:\"query,required\"`" + `
+	Ratio   float64 ` + "`bind:\"query\"`" + `
+	Verbose bool    ` + "`bind:\"header\"`" + `
+	Name    string  ` + "`bind:\"header,required\"`" + `
+	Session int64   ` + "`bind:\"cookie\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{
+		"/?Limit=5",
+		"/",
+		"/?Limit=5&Page=",
+		"/?Limit=5&Page=abc",
+		"/?Limit=300",
+		"/?Limit=5&Page=2&Ratio=0.5",
+	} {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header.Set("Name", "gopher")
+		var p Params
+		err := BindParams(r, &p)
+		fmt.Printf("%s: %+v %v\n", target, p, err)
+	}
+
+	r := httptest.NewRequest("GET", "/?Limit=1", nil)
+	r.Header.Set("Verbose", "maybe")
+	fmt.Println(BindParams(r, &Params{}))
+
+	r = httptest.NewRequest("GET", "/?Limit=1", nil)
+	r.Header.Set("Name", "gopher")
+	r.Header.Set("Cookie", "Session=x")
+	fmt.Println(BindParams(r, &Params{}))
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `/?Limit=5: {Page:0 Limit:5 Ratio:0 Verbose:false Name:gopher Session:0} <nil>
+/: {Page:0 Limit:0 Ratio:0 Verbose:false Name:gopher Session:0} Limit is required
+/?Limit=5&Page=: {Page:0 Limit:5 Ratio:0 Verbose:false Name:gopher Session:0} <nil>
+/?Limit=5&Page=abc: {Page:0 Limit:5 Ratio:0 Verbose:false Name:gopher Session:0} Page must be a valid integer
+/?Limit=300: {Page:0 Limit:0 Ratio:0 Verbose:false Name:gopher Session:0} Limit is out of range for uint8
+/?Limit=5&Page=2&Ratio=0.5: {Page:2 Limit:5 Ratio:0.5 Verbose:false Name:gopher Session:0} <nil>
+Verbose must be a boolean; Name is required
+Session must be a valid integer
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EPointerFields(t *testing.T) {
+	source := `package main
+
+type Filter struct {
+	Page   *int    ` + "`bind:\"query,required\" validate:\"min=1\"`" + `
+	Active *bool   ` + "`bind:\"query\"`" + `
+	Search *string ` + "`bind:\"query\"`" + `
+	Limit  *uint16 ` + "`bind:\"header\" validate:\"max=100\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func show[T any](p *T) string {
+	if p == nil {
+		return "nil"
+	}
+	return fmt.Sprint(*p)
+}
+
+func main() {
+	for _, target := range []string{
+		"/?Page=0",
+		"/?Page=2&Active=false&Search=go",
+		"/?Active=true",
+		"/?Page=1&Active=no",
+	} {
+		r := httptest.NewRequest("GET", target, nil)
+		var f Filter
+		err := BindFilter(r, &f)
+		if err == nil {
+			err = ValidateFilter(&f)
+		}
+		fmt.Printf("%s: Page=%s Active=%s Search=%s Limit=%s %v\n", target, show(f.Page), show(f.Active), show(f.Search), show(f.Limit), err)
+	}
+
+	r := httptest.NewRequest("GET", "/?Page=1", nil)
+	r.Header.Set("Limit", "500")
+	var f Filter
+	fmt.Println(BindFilter(r, &f), ValidateFilter(&f))
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `/?Page=0: Page=0 Active=nil Search=nil Limit=nil Page must be at least 1
+/?Page=2&Active=false&Search=go: Page=2 Active=false Search=go Limit=nil <nil>
+/?Active=true: Page=nil Active=true Search=nil Limit=nil Page is required
+/?Page=1&Active=no: Page=1 Active=nil Search=nil Limit=nil Active must be a boolean
+<nil> Limit must be at most 100
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2ESliceFields(t *testing.T) {
+	source := `package main
+
+type Search struct {
+	Tags   []string  ` + "`bind:\"query=tag\" validate:\"max=3\"`" + `
+	IDs    []int64   ` + "`bind:\"query=ids,explode=false,required\" validate:\"min=2\"`" + `
+	Scores []float32 ` + "`bind:\"header=X-Score\"`" + `
+	Langs  []string  ` + "`bind:\"header=Accept-Language,explode=false\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{
+		"/?tag=a&tag=b&ids=1,2,3",
+		"/?ids=4&ids=5,6",
+		"/?tag=a",
+		"/?ids=1,x",
+		"/?ids=7",
+		"/?ids=1,2&tag=a&tag=b&tag=c&tag=d",
+	} {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header.Add("X-Score", "1.5")
+		r.Header.Add("X-Score", "2")
+		r.Header.Set("Accept-Language", "en, fr")
+		var s Search
+		err := BindSearch(r, &s)
+		if err == nil {
+			err = ValidateSearch(&s)
+		}
+		fmt.Printf("%s: %q %v %v %q %v\n", target, s.Tags, s.IDs, s.Scores, s.Langs, err)
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `/?tag=a&tag=b&ids=1,2,3: ["a" "b"] [1 2 3] [1.5 2] ["en" "fr"] <nil>
+/?ids=4&ids=5,6: [] [4 5 6] [1.5 2] ["en" "fr"] <nil>
+/?tag=a: ["a"] [] [1.5 2] ["en" "fr"] ids is required
+/?ids=1,x: [] [1] [1.5 2] ["en" "fr"] ids must be a valid integer
+/?ids=7: [] [7] [1.5 2] ["en" "fr"] IDs must contain at least 2 items
+/?ids=1,2&tag=a&tag=b&tag=c&tag=d: ["a" "b" "c" "d"] [1 2] [1.5 2] ["en" "fr"] Tags must contain at most 3 items
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EDefaults(t *testing.T) {
+	source := `package main
+
+type ListParams struct {
+	Limit  int      ` + "`bind:\"query=limit,default=20\" validate:\"max=100\"`" + `
+	Sort   string   ` + "`bind:\"query=sort,default=created_at\"`" + `
+	Desc   *bool    ` + "`bind:\"query=desc,default=true\"`" + `
+	Fields []string ` + "`bind:\"query=fields,explode=false,default=id|name\"`" + `
+	Tenant string   ` + "`bind:\"header=X-Tenant,default=public\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{
+		"/",
+		"/?limit=50&sort=name&desc=false&fields=email",
+		"/?limit=",
+		"/?limit=abc",
+	} {
+		r := httptest.NewRequest("GET", target, nil)
+		var s ListParams
+		if err := BindListParams(r, &s); err != nil {
+			fmt.Printf("%s: %v\n", target, err)
+			continue
+		}
+		fmt.Printf("%s: %d %s %v %q %s\n", target, s.Limit, s.Sort, *s.Desc, s.Fields, s.Tenant)
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `/: 20 created_at true ["id" "name"] public
+/?limit=50&sort=name&desc=false&fields=email: 50 name false ["email"] public
+/?limit=: 20 created_at true ["id" "name"] public
+/?limit=abc: limit must be a valid integer
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EErrors(t *testing.T) {
+	source := `package main
+
+type Search struct {
+	Query string   ` + "`bind:\"query=q,required\"`" + `
+	Page  int      ` + "`bind:\"query=page\" validate:\"min=1\"`" + `
+	Tags  []string ` + "`bind:\"query=tag\" validate:\"max=2\"`" + `
+	Limit int      ` + "`validate:\"max=100\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+
+	"github.com/pangobit/go-wrangler/wrangler"
+)
+
+func main() {
+	r := httptest.NewRequest("GET", "/?page=x&tag=a&tag=b&tag=c", nil)
+	s := Search{Limit: 500}
+	bindErr := BindSearch(r, &s)
+	validateErr := ValidateSearch(&s)
+	for _, err := range []error{bindErr, validateErr} {
+		var errs wrangler.Errors
+		if !errors.As(err, &errs) {
+			fmt.Println("not wrangler.Errors:", err)
+			continue
+		}
+		for _, fe := range errs {
+			fmt.Printf("%s %s %s %s: %s\n", fe.Field, fe.Param, fe.Source, fe.Rule, fe.Message)
+		}
+		var fe *wrangler.FieldError
+		fmt.Println(errors.As(err, &fe), fe.Field)
+	}
+}
+`
+	tests := []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{
+			name: "collect",
+			want: `Query q query required: q is required
+Page page query type: page must be a valid integer
+true Query
+Page page query min: Page must be at least 1
+Tags tag query max: Tags must contain at most 2 items
+Limit   max: Limit must be at most 100
+true Page
+`,
+		},
+		{
+			name: "fail fast",
+			opts: Options{FailFast: true},
+			want: `Query q query required: q is required
+true Query
+Page page query min: Page must be at least 1
+true Page
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runGenerated(t, source, main, tt.opts)
+			if got != tt.want {
+				t.Errorf("output = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestE2ENonFiniteFloats(t *testing.T) {
+	// NaN and infinities parse as floats, but would pass any bound, so they aren't bound
+	source := `package main
+
+type Req struct {
+	Ratio  float64   ` + "`bind:\"query\" validate:\"min=0,max=1\"`" + `
+	Scale  *float32  ` + "`bind:\"header=X-Scale\"`" + `
+	Scores []float64 ` + "`bind:\"query=score\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{"/?Ratio=0.5&score=1", "/?Ratio=NaN&score=-Inf", "/?Ratio=Infinity"} {
+		r := httptest.NewRequest("GET", target, nil)
+		r.Header.Set("X-Scale", "+Inf")
+		var s Req
+		fmt.Println(BindReq(r, &s), s.Ratio, s.Scale, s.Scores)
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `X-Scale must be a valid number 0.5 <nil> [1]
+Ratio must be a valid number; X-Scale must be a valid number; score must be a valid number 0 <nil> []
+Ratio must be a valid number; X-Scale must be a valid number 0 <nil> []
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EBodyErrors(t *testing.T) {
+	source := `package main
+
+type CreateUser struct {
+	Name string ` + "`json:\"name\" bind:\"body,required,maxbytes=64\"`" + `
+	Org  string ` + "`bind:\"header=X-Org,required\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pangobit/go-wrangler/wrangler"
+)
+
+func main() {
+	for _, body := range []string{
+		"{}",
+		"{\"name\": ",
+		"{\"name\": \"" + strings.Repeat("x", 100) + "\"}",
+	} {
+		r := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		r.Header.Set("Content-Type", "application/json")
+		err := BindCreateUser(r, &CreateUser{})
+		var fe *wrangler.FieldError
+		errors.As(err, &fe)
+		var maxErr *http.MaxBytesError
+		fmt.Printf("%s %s: %v %v\n", fe.Source, fe.Rule, err, errors.As(err, &maxErr))
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `body required: name is required; X-Org is required false
+body json: invalid JSON request body: unexpected EOF false
+body maxbytes: request body must not exceed 64 bytes true
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EStringRules(t *testing.T) {
+	source := `package main
+
+type Signup struct {
+	Username string  ` + "`bind:\"query=username\" validate:\"minlen=3,maxlen=8,pattern=^[a-z]+$\"`" + `
+	Country  *string ` + "`bind:\"query=country\" validate:\"len=2\"`" + `
+	Sort     string  ` + "`bind:\"query=sort,default=asc\" validate:\"oneof=asc|desc\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{
+		"/?username=gopher",
+		"/?username=g%C3%B6pher&country=DE&sort=desc",
+		"/?username=go&country=DEU&sort=up",
+		"/?username=gophers_1",
+	} {
+		var s Signup
+		if err := BindSignup(httptest.NewRequest("GET", target, nil), &s); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Printf("%s: %v\n", target, ValidateSignup(&s))
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `/?username=gopher: <nil>
+/?username=g%C3%B6pher&country=DE&sort=desc: Username must match the pattern ^[a-z]+$
+/?username=go&country=DEU&sort=up: Username must be at least 3 characters long; Country must be exactly 2 characters long; Sort must be one of asc, desc
+/?username=gophers_1: Username must be at most 8 characters long; Username must match the pattern ^[a-z]+$
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EFormats(t *testing.T) {
+	source := `package main
+
+type Webhook struct {
+	Email    string  ` + "`bind:\"query=email\" validate:\"format=email\"`" + `
+	Callback string  ` + "`bind:\"query=callback\" validate:\"format=url\"`" + `
+	ID       string  ` + "`bind:\"query=id\" validate:\"format=uuid\"`" + `
+	Since    *string ` + "`bind:\"query=since\" validate:\"format=date-time\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{
+		"/?email=gopher@example.com&callback=https://example.com/hook&id=123e4567-e89b-12d3-a456-426614174000",
+		"/?email=gopher&callback=example.com&id=123&since=yesterday",
+	} {
+		var s Webhook
+		if err := BindWebhook(httptest.NewRequest("GET", target, nil), &s); err != nil {
+			fmt.Println(err)
+			continue
+		}
+		fmt.Println(ValidateWebhook(&s))
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `<nil>
+Email must be a valid email address; Callback must be a valid URL; ID must be a valid UUID; Since must be a valid RFC 3339 date-time
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EBounds(t *testing.T) {
+	source := `package main
+
+import "time"
+
+type Job struct {
+	Weight  float32       ` + "`validate:\"gt=0,lte=1.5\"`" + `
+	Retries *int8         ` + "`validate:\"gte=0,lt=10\"`" + `
+	Timeout time.Duration ` + "`validate:\"min=500ms,max=1h\"`" + `
+	RunAt   time.Time     ` + "`validate:\"gt=2024-01-01T00:00:00Z,lt=2100-01-01\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	retries := int8(10)
+	for _, job := range []Job{
+		{Weight: 1.5, Timeout: time.Second, RunAt: time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Weight: 0, Retries: &retries, Timeout: 100 * time.Millisecond, RunAt: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{Weight: 1.6, Timeout: 2 * time.Hour, RunAt: time.Date(2100, 1, 1, 0, 0, 0, 0, time.UTC)},
+	} {
+		fmt.Println(ValidateJob(&job))
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `<nil>
+Weight must be greater than 0; Retries must be less than 10; Timeout must be at least 500ms; RunAt must be after 2024-01-01T00:00:00Z
+Weight must be at most 1.5; Timeout must be at most 1h; RunAt must be before 2100-01-01
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EZeroDurationBounds(t *testing.T) {
+	// Zero bounds are written as 0, so the generated file doesn't import time
+	source := `package main
+
+import "time"
+
+type Job struct {
+	Timeout time.Duration ` + "`validate:\"min=0\"`" + `
+	Delay   time.Duration ` + "`validate:\"max=0s\"`" + `
+}
+`
+	main := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(ValidateJob(&Job{Timeout: 1, Delay: 0}))
+	fmt.Println(ValidateJob(&Job{Timeout: -1, Delay: 1}))
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `<nil>
+Timeout must be at least 0; Delay must be at most 0s
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2ECrossFieldRules(t *testing.T) {
+	source := `package main
+
+import "time"
+
+type Search struct {
+	From     *time.Time ` + "`json:\"from\"`" + `
+	To       *time.Time ` + "`json:\"to\" validate:\"gtfield=From\"`" + `
+	Min      int        ` + "`json:\"min\"`" + `
+	Max      int        ` + "`json:\"max\" validate:\"gtefield=Min\"`" + `
+	Mode     string     ` + "`json:\"mode\"`" + `
+	Depth    *int       ` + "`json:\"depth\" validate:\"required_if=Mode:advanced\"`" + `
+	Email    string     ` + "`json:\"email\" validate:\"required_without=Phone\"`" + `
+	Phone    string     ` + "`json:\"phone\" validate:\"excluded_with=Email\"`" + `
+	Password string     ` + "`json:\"password\"`" + `
+	Confirm  string     ` + "`json:\"confirm\" validate:\"eqfield=Password\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"time"
+)
+
+func main() {
+	from := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	to := from.Add(-time.Hour)
+	depth := 2
+	for _, search := range []Search{
+		{From: &from, Min: 1, Max: 1, Mode: "advanced", Depth: &depth, Email: "gopher@example.com", Password: "secret", Confirm: "secret"},
+		{To: &to, Phone: "555-0100"},
+		{From: &from, To: &to, Min: 2, Max: 1, Mode: "advanced", Email: "gopher@example.com", Phone: "555-0100", Password: "secret"},
+		{},
+	} {
+		fmt.Println(ValidateSearch(&search))
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	want := `<nil>
+<nil>
+To must be after From; Max must be greater than or equal to Min; Depth is required when Mode is advanced; Phone must not be set when Email is set; Confirm must equal Password
+Email is required when Phone is not set
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EValidateHooks(t *testing.T) {
+	source := `package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pangobit/go-wrangler/wrangler"
+)
+
+type Order struct {
+	Qty   int ` + "`validate:\"min=1\"`" + `
+	Price int ` + "`validate:\"min=0\"`" + `
+	Total int
+}
+
+func (o *Order) Validate() error {
+	if o.Total != o.Qty*o.Price {
+		return &wrangler.FieldError{Field: "Total", Rule: "total", Message: "Total must be Qty times Price"}
+	}
+	return nil
+}
+
+func (o Order) ValidateContext(ctx context.Context) error {
+	if ctx.Value("closed") != nil {
+		return errors.New("orders are closed")
+	}
+	return nil
+}
+`
+	main := `package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/pangobit/go-wrangler/wrangler"
+)
+
+func main() {
+	fmt.Println(ValidateOrder(&Order{Qty: 2, Price: 3, Total: 6}))
+	fmt.Println(ValidateOrder(&Order{Qty: 0, Price: 3, Total: 1}))
+
+	err := ValidateOrderContext(context.WithValue(context.Background(), "closed", true), &Order{Qty: 1, Price: 1, Total: 1})
+	var fe *wrangler.FieldError
+	fmt.Println(err, errors.As(err, &fe) && fe.Rule == "validate")
+}
+`
+	for _, tt := range []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"collect", Options{}, "<nil>\nQty must be at least 1; Total must be Qty times Price\norders are closed true\n"},
+		{"fail fast", Options{FailFast: true}, "<nil>\nQty must be at least 1\norders are closed true\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runGenerated(t, source, main, tt.opts); got != tt.want {
+				t.Errorf("output = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestE2EFlattenedValidateHooks(t *testing.T) {
+	source := `package main
+
+import (
+	"context"
+	"errors"
+
+	"github.com/pangobit/go-wrangler/wrangler"
+)
+
+type Pagination struct {
+	Page  int ` + "`validate:\"min=1\"`" + `
+	Limit int
+}
+
+func (p Pagination) Validate() error {
+	if p.Page > 1 && p.Limit > 50 {
+		return &wrangler.FieldError{Field: "Limit", Rule: "limit", Message: "Limit must be at most 50 past the first page"}
+	}
+	return nil
+}
+
+type Range struct {
+	From int
+	To   int
+}
+
+func (r *Range) ValidateContext(ctx context.Context) error {
+	if r.From > r.To {
+		return errors.New("From must not be after To")
+	}
+	return nil
+}
+
+type Filter struct {
+	Pagination
+	Range Range
+}
+
+type List struct {
+	Pagination
+	Filter Filter
+}
+`
+	main := `package main
+
+import (
+	"context"
+	"fmt"
+)
+
+func main() {
+	fmt.Println(ValidateList(&List{Pagination{1, 100}, Filter{Pagination{1, 10}, Range{1, 2}}}))
+	fmt.Println(ValidateListContext(context.Background(), &List{Pagination{2, 100}, Filter{Pagination{3, 60}, Range{2, 1}}}))
+}
+`
+	for _, tt := range []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"collect", Options{}, "<nil>\nLimit must be at most 50 past the first page; Filter.Limit must be at most 50 past the first page; Filter.Range: From must not be after To\n"},
+		{"fail fast", Options{FailFast: true}, "<nil>\nLimit must be at most 50 past the first page\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runGenerated(t, source, main, tt.opts); got != tt.want {
+				t.Errorf("output = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestE2ECustomValidators(t *testing.T) {
+	sources := map[string]string{
+		"sku/sku.go": `package sku
+
+import (
+	"errors"
+	"strings"
+)
+
+type Code string
+
+func Validate(s string) error {
+	if !strings.HasPrefix(s, "SKU-") {
+		return errors.New("must start with SKU-")
+	}
+	return nil
+}
+
+func ValidateCode(c Code) error {
+	if len(c) != 3 {
+		return errors.New("must be 3 characters long")
+	}
+	return nil
+}
+`,
+		"types.go": `package main
+
+import (
+	"errors"
+	"strings"
+
+	"e2e/sku"
+)
+
+//wrangler:validator sku e2e/sku.Validate
+//wrangler:validator code sku.ValidateCode
+//wrangler:validator tenant_slug validateSlug
+
+var errUppercase = errors.New("must be lowercase")
+
+func validateSlug(s string) error {
+	if strings.ToLower(s) != s {
+		return errUppercase
+	}
+	return nil
+}
+
+type Item struct {
+	SKU    *string  ` + "`validate:\"sku,maxlen=12\"`" + `
+	Code   sku.Code ` + "`validate:\"code\"`" + `
+	Tenant string   ` + "`validate:\"tenant_slug\"`" + `
+}
+`,
+	}
+	main := `package main
+
+import (
+	"errors"
+	"fmt"
+)
+
+func main() {
+	good, bad := "SKU-1", "ABC-123456789"
+	fmt.Println(ValidateItem(&Item{SKU: &good, Code: "abc", Tenant: "acme"}))
+	err := ValidateItem(&Item{SKU: &bad, Code: "abcd", Tenant: "Acme"})
+	fmt.Println(err, errors.Is(err, errUppercase))
+}
+`
+	got := runGeneratedFiles(t, sources, main, Options{})
+	want := `<nil>
+SKU must be at most 12 characters long; SKU: must start with SKU-; Code: must be 3 characters long; Tenant: must be lowercase true
+`
+	if got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2ENestedStructs(t *testing.T) {
+	sources := map[string]string{
+		"pagination.go": `package main
+
+type Pagination struct {
+	Page  int ` + "`bind:\"query=page,default=1\" validate:\"min=1\"`" + `
+	Limit int ` + "`bind:\"query=limit,default=20\"`" + `
+}
+`,
+		"types.go": `package main
+
+type Filter struct {
+	Status string ` + "`bind:\"query=status\" validate:\"oneof=active|disabled\"`" + `
+	From   int    ` + "`bind:\"query=from\"`" + `
+	To     *int   ` + "`bind:\"query=to\" validate:\"gtfield=From\"`" + `
+}
+
+type ListUsers struct {
+	Pagination
+	Filter Filter ` + "`prefix:\"filter[...]\"`" + `
+	Sort   Sort   ` + "`prefix:\"sort.\"`" + `
+}
+
+type Sort struct {
+	By string ` + "`bind:\"query=by,default=name\"`" + `
+}
+`,
+	}
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func main() {
+	for _, target := range []string{
+		"/users?filter[status]=active&sort.by=email&page=2",
+		"/users?filter[status]=deleted&filter[from]=5&filter[to]=3&page=0",
+	} {
+		var req ListUsers
+		err := BindListUsers(httptest.NewRequest("GET", target, nil), &req)
+		if err == nil {
+			err = ValidateListUsers(&req)
+		}
+		fmt.Printf("%+v %v\n", req, err)
+	}
+}
+`
+	got := runGeneratedFiles(t, sources, main, Options{})
+	want := `{Pagination:{Page:2 Limit:20} Filter:{Status:active From:0 To:<nil>} Sort:{By:email}} <nil>
+`
+	if !strings.HasPrefix(got, want) || !strings.HasSuffix(got, "Page must be at least 1; Filter.Status must be one of active, disabled; Filter.To must be greater than Filter.From\n") {
+		t.Errorf("output = %s, want %s...", got, want)
+	}
+}
+
+func TestE2ENestedValidation(t *testing.T) {
+	source := `package main
+
+type Item struct {
+	SKU      string ` + "`json:\"sku\" validate:\"minlen=3\"`" + `
+	Quantity int    ` + "`json:\"quantity\" validate:\"min=1\"`" + `
+}
+
+type Category struct {
+	Name     string      ` + "`json:\"name\" validate:\"minlen=1\"`" + `
+	Children []*Category ` + "`json:\"children\"`" + `
+}
+
+type Order struct {
+	Customer string          ` + "`bind:\"query=customer\" validate:\"minlen=1\"`" + `
+	Items    []Item          ` + "`bind:\"body\" json:\"items\"`" + `
+	Gifts    map[string]Item ` + "`bind:\"body\" json:\"gifts\"`" + `
+	Category *Category       ` + "`bind:\"body\" json:\"category\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/pangobit/go-wrangler/wrangler"
+)
+
+func main() {
+	body := ` + "`" + `{
+		"items": [{"sku": "ABC", "quantity": 1}, {"sku": "X", "quantity": 0}],
+		"gifts": {"b": {"sku": "GIFT", "quantity": 0}, "a": {"sku": "G", "quantity": 1}},
+		"category": {"name": "root", "children": [{"name": "books"}, null, {"name": "", "children": [{"name": ""}]}]}
+	}` + "`" + `
+	r := httptest.NewRequest("POST", "/?customer=ann", strings.NewReader(body))
+	r.Header.Set("Content-Type", "application/json")
+	var order Order
+	if err := BindOrder(r, &order); err != nil {
+		fmt.Println("bind:", err)
+		return
+	}
+	err := ValidateOrder(&order)
+	fmt.Println(err)
+	var errs wrangler.Errors
+	if errors.As(err, &errs) {
+		fmt.Println(errs[0].Field, errs[0].Rule)
+	}
+}
+`
+	for _, tt := range []struct {
+		name string
+		opts Options
+		want string
+	}{
+		{"collect", Options{}, "Items[1].SKU must be at least 3 characters long; Items[1].Quantity must be at least 1; " +
+			"Gifts[a].SKU must be at least 3 characters long; Gifts[b].Quantity must be at least 1; " +
+			"Category.Children[2].Name must be at least 1 characters long; Category.Children[2].Children[0].Name must be at least 1 characters long\n" +
+			"Items[1].SKU minlen\n"},
+		{"fail fast", Options{FailFast: true}, "Items[1].SKU must be at least 3 characters long\nItems[1].SKU minlen\n"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := runGenerated(t, source, main, tt.opts); got != tt.want {
+				t.Errorf("output = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestE2ENestedMapNamedKeys(t *testing.T) {
+	// Keys of named string and number types are sorted too, so failures keep their order
+	source := `package main
+
+type Status string
+
+type Priority int
+
+type Ticket struct {
+	Title string ` + "`validate:\"minlen=1\"`" + `
+}
+
+type Board struct {
+	ByStatus   map[Status]Ticket
+	ByPriority map[Priority]*Ticket
+}
+`
+	main := `package main
+
+import "fmt"
+
+func main() {
+	board := Board{
+		ByStatus:   map[Status]Ticket{"open": {}, "closed": {}, "blocked": {}, "done": {Title: "Ship"}, "review": {}},
+		ByPriority: map[Priority]*Ticket{3: {}, 1: {}, 2: {}, 10: {}},
+	}
+	for range 5 {
+		fmt.Println(ValidateBoard(&board))
+	}
+}
+`
+	got := runGenerated(t, source, main, Options{})
+	line := "ByStatus[blocked].Title must be at least 1 characters long; ByStatus[closed].Title must be at least 1 characters long; " +
+		"ByStatus[open].Title must be at least 1 characters long; ByStatus[review].Title must be at least 1 characters long; " +
+		"ByPriority[1].Title must be at least 1 characters long; ByPriority[2].Title must be at least 1 characters long; " +
+		"ByPriority[3].Title must be at least 1 characters long; ByPriority[10].Title must be at least 1 characters long\n"
+	if want := strings.Repeat(line, 5); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2ETextUnmarshaler(t *testing.T) {
+	source := `package main
+
+import (
+	"errors"
+	"net/netip"
+	"strings"
+	"time"
+)
+
+type OrderID string
+
+func (id *OrderID) UnmarshalText(b []byte) error {
+	if !strings.HasPrefix(string(b), "ord_") {
+		return errors.New("must start with ord_")
+	}
+	*id = OrderID(b)
+	return nil
+}
+
+type Lookup struct {
+	ID    OrderID        ` + "`bind:\"path=id\"`" + `
+	IP    *netip.Addr    ` + "`bind:\"header=X-IP\"`" + `
+	Nets  []netip.Prefix ` + "`bind:\"query=net,explode=false\"`" + `
+	Since time.Time      ` + "`bind:\"query=since\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func bind(id, ip, query string) {
+	r := httptest.NewRequest("GET", "/orders?"+query, nil)
+	r.SetPathValue("id", id)
+	r.Header.Set("X-IP", ip)
+	var l Lookup
+	if err := BindLookup(r, &l); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(l.ID, *l.IP, l.Nets, l.Since.UTC().Format("2006-01-02"))
+}
+
+func main() {
+	bind("ord_1", "10.0.0.1", "net=10.0.0.0/8,fd00::/8&since=2024-05-01T10:00:00Z")
+	bind("1", "10.0.0", "net=10.0.0.0&since=yesterday")
+}
+`
+	want := "ord_1 10.0.0.1 [10.0.0.0/8 fd00::/8] 2024-05-01\n" +
+		`id is invalid: must start with ord_; X-IP is invalid: ParseAddr("10.0.0"): IPv4 address too short; ` +
+		`net is invalid: netip.ParsePrefix("10.0.0.0"): no '/'; ` +
+		`since must be a time formatted as 2006-01-02T15:04:05Z07:00` + "\n"
+	if got := runGenerated(t, source, main, Options{}); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2ETimes(t *testing.T) {
+	source := `package main
+
+import "time"
+
+type ListEvents struct {
+	Since   time.Time     ` + "`bind:\"query=since\"`" + `
+	Day     *time.Time    ` + "`bind:\"query=day,layout=date\"`" + `
+	At      time.Time     ` + "`bind:\"query=at,layout=unix\"`" + `
+	Timeout time.Duration ` + "`bind:\"query=timeout,default=30s\" validate:\"max=1m\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"time"
+)
+
+func bind(query string) {
+	var l ListEvents
+	if err := BindListEvents(httptest.NewRequest("GET", "/events?"+query, nil), &l); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := ValidateListEvents(&l); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(l.Since.Format(time.RFC3339), l.Day.Format(time.DateOnly), l.At.UTC().Format(time.RFC3339), l.Timeout)
+}
+
+func main() {
+	bind("since=2024-05-01T10:00:00%2B02:00&day=2024-05-02&at=1700000000")
+	bind("since=2024-05-01&day=02/05/2024&at=now&timeout=soon")
+	bind("since=2024-05-01T10:00:00Z&day=2024-05-02&at=0&timeout=2m")
+}
+`
+	want := "2024-05-01T10:00:00+02:00 2024-05-02 2023-11-14T22:13:20Z 30s\n" +
+		"since must be a time formatted as 2006-01-02T15:04:05Z07:00; day must be a time formatted as 2006-01-02; " +
+		"at must be a Unix time in seconds; timeout must be a duration such as 1h30m or 500ms\n" +
+		"Timeout must be at most 1m\n"
+	if got := runGenerated(t, source, main, Options{}); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2ENamedTypes(t *testing.T) {
+	source := `package main
+
+import "net"
+
+type UserID int
+
+type Status string
+
+type Tag = string
+
+type Search struct {
+	User    UserID   ` + "`bind:\"path=user\" validate:\"min=1\"`" + `
+	Friends []UserID ` + "`bind:\"query=friend,default=1|2\"`" + `
+	Manager *UserID  ` + "`bind:\"query=manager\" validate:\"nefield=User\"`" + `
+	Status  Status   ` + "`bind:\"query=status,default=active\" validate:\"oneof=active|archived,maxlen=8\"`" + `
+	Tags    []Tag    ` + "`bind:\"query=tag\"`" + `
+	Gateway net.IP   ` + "`bind:\"header=X-Gateway\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func bind(user, gateway, query string) {
+	r := httptest.NewRequest("GET", "/users?"+query, nil)
+	r.SetPathValue("user", user)
+	r.Header.Set("X-Gateway", gateway)
+	var s Search
+	if err := BindSearch(r, &s); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := ValidateSearch(&s); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println(s.User, s.Friends, *s.Manager, s.Status, s.Tags, s.Gateway)
+}
+
+func main() {
+	bind("7", "10.0.0.1", "manager=3&tag=a&tag=b")
+	bind("7", "10.0.0.1", "manager=7&status=deleted")
+	bind("0", "10.0.0", "friend=x")
+}
+`
+	want := "7 [1 2] 3 active [a b] 10.0.0.1\n" +
+		"Manager must not equal User; Status must be one of active, archived\n" +
+		"friend must be a valid integer; X-Gateway is invalid: invalid IP address: 10.0.0\n"
+	if got := runGenerated(t, source, main, Options{}); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EEscapedTagValues(t *testing.T) {
+	source := `package main
+
+type Search struct {
+	Code  string   ` + "`bind:\"query=code\" validate:\"pattern=^[a-z]{2\\\\,4}$\"`" + `
+	Sort  string   ` + "`bind:\"query=sort,default=name asc\" validate:\"oneof=name asc|name desc\"`" + `
+	Tags  []string ` + "`bind:\"query=tag,default=a\\\\,b|c\\\\|d\"`" + `
+	Place string   ` + "`bind:\"query=place\" validate:\"oneof=Paris\\\\, France|Rome\"`" + `
+}
+`
+	main := `package main
+
+import (
+	"fmt"
+	"net/http/httptest"
+)
+
+func bind(query string) {
+	r := httptest.NewRequest("GET", "/search?"+query, nil)
+	var s Search
+	if err := BindSearch(r, &s); err != nil {
+		fmt.Println(err)
+		return
+	}
+	if err := ValidateSearch(&s); err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("%s|%s|%q|%s\n", s.Code, s.Sort, s.Tags, s.Place)
+}
+
+func main() {
+	bind("code=abc&place=Paris,+France")
+	bind("code=abcdef&sort=name&place=Paris")
+}
+`
+	want := `abc|name asc|["a,b" "c|d"]|Paris, France` + "\n" +
+		"Code must match the pattern ^[a-z]{2,4}$; Sort must be one of name asc, name desc; Place must be one of Paris, France, Rome\n"
+	if got := runGenerated(t, source, main, Options{}); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
+
+func TestE2EPatternNames(t *testing.T) {
+	source := `package main
+
+type AB struct {
+	C string ` + "`validate:\"pattern=^c$\"`" + `
+}
+
+type A struct {
+	BC string ` + "`validate:\"pattern=^bc$\"`" + `
+}
+`
+	main := `package main
+
+import "fmt"
+
+func main() {
+	fmt.Println(ValidateAB(&AB{C: "c"}), ValidateA(&A{BC: "c"}))
+}
+`
+	want := "<nil> BC must match the pattern ^bc$\n"
+	if got := runGenerated(t, source, main, Options{}); got != want {
+		t.Errorf("output = %s, want %s", got, want)
+	}
+}
@@ -2,149 +2,134 @@
 package generator
 
 import (
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"go/format"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/pangobit/go-wrangler/internal/parse"
 )
 
-// GenerateBindFunction generates Go code for a bind function that takes an http.Request and path params,
-// binds them to the struct fields according to the bind tags.
-func GenerateBindFunction(structInfo parse.StructInfo) (string, []string) {
+// Options configures the generated code
+// FailFast makes the generated functions return on the first failure, rather than collecting
+// the failures of every field, for hot paths that don't report them all.
+// Version, Command and SourceHash record where a generated file comes from in its header: the
+// version of go-wrangler, the command line that generated it and, when SourceHash is set, a hash
+// of the structs it was generated from. Each is left out when empty or unset.
+type Options struct {
+	FailFast   bool
+	Version    string
+	Command    string
+	SourceHash bool
+}
+
+// GeneratePackage generates Go code for bind and validate functions for multiple structs, in the
+// order of structs. The code is formatted with go/format, and its imports sorted, so that the same
+// structs always generate the same bytes. The file starts with the standard header of generated
+// files, before the package clause, followed by the provenance recorded by opts.
+func GeneratePackage(structs []parse.StructInfo, pkgName string, opts Options) (string, error) {
 	var sb strings.Builder
+	if err := writeHeader(&sb, structs, opts); err != nil {
+		return "", err
+	}
+	sb.WriteString("package " + pkgName + "\n\n")
 
-	sb.WriteString("// Code generated by go-wrangler. DO NOT EDIT.\n\n")
+	var imports []string
+	var functions []string
 
-	needsStrconv := false
-	for _, tag := range structInfo.Tags {
-		if tag.Bind != nil && tag.FieldType == "int" {
-			needsStrconv = true
+	for _, s := range structs {
+		bindCode, bindImports, err := GenerateBindFunction(s, opts)
+		if err != nil {
+			return "", err
 		}
-	}
+		functions = append(functions, bindCode)
+		imports = append(imports, bindImports...)
 
-	imports := []string{"fmt", "net/http"}
-	if needsStrconv {
-		imports = append(imports, "strconv")
+		validateCode, validateImports, err := GenerateValidateFunction(s, opts)
+		if err != nil {
+			return "", err
+		}
+		functions = append(functions, validateCode)
+		imports = append(imports, validateImports...)
 	}
 
-	// Function signature
-	sb.WriteString(fmt.Sprintf("func Bind%s(r *http.Request, s *%s) error {\n", structInfo.Name, structInfo.Name))
+	writeImports(&sb, imports)
 
-	// Bind logic
-	for _, tag := range structInfo.Tags {
-		if tag.Bind != nil {
-			var valueExpr string
-			switch tag.Bind.Type {
-			case "query":
-				valueExpr = fmt.Sprintf("r.URL.Query().Get(\"%s\")", tag.FieldName)
-			case "header":
-				valueExpr = fmt.Sprintf("r.Header.Get(\"%s\")", tag.FieldName)
-			case "path":
-				valueExpr = fmt.Sprintf("r.PathValue(\"%s\")", tag.FieldName)
-			}
-			if tag.FieldType == "int" {
-				sb.WriteString(fmt.Sprintf("\tif val, err := strconv.Atoi(%s); err != nil {\n\t\treturn fmt.Errorf(\"%s must be a valid integer\")\n\t} else {\n\t\ts.%s = val\n\t}\n", valueExpr, tag.FieldName, tag.FieldName))
-			} else {
-				sb.WriteString(fmt.Sprintf("\ts.%s = %s\n", tag.FieldName, valueExpr))
-			}
-			if tag.Bind.Required {
-				if tag.FieldType == "int" {
-					sb.WriteString(fmt.Sprintf("\tif s.%s == 0 {\n\t\treturn fmt.Errorf(\"%s is required\")\n\t}\n", tag.FieldName, tag.FieldName))
-				} else {
-					sb.WriteString(fmt.Sprintf("\tif s.%s == \"\" {\n\t\treturn fmt.Errorf(\"%s is required\")\n\t}\n", tag.FieldName, tag.FieldName))
-				}
-			}
-		}
+	for _, fn := range functions {
+		sb.WriteString(fn)
+		sb.WriteString("\n")
 	}
 
-	sb.WriteString("\treturn nil\n}\n")
-
-	return sb.String(), imports
+	code, err := format.Source([]byte(sb.String()))
+	if err != nil {
+		return "", fmt.Errorf("generated code doesn't format: %w\n%s", err, numberLines(sb.String()))
+	}
+	return string(code), nil
 }
 
-// GenerateValidateFunction generates Go code for a validate function that validates the struct fields according to the validate tags.
-func GenerateValidateFunction(structInfo parse.StructInfo) (string, []string) {
-	var sb strings.Builder
-
-	sb.WriteString("// Code generated by go-wrangler. DO NOT EDIT.\n\n")
-
-	// Check if strconv is needed
-	needsStrconv := false
-	for _, tag := range structInfo.Tags {
-		if tag.Validate != nil && tag.FieldType != "int" {
-			needsStrconv = true
-		}
+// writeHeader writes the comment marking the file as generated, matching the
+// ^// Code generated .* DO NOT EDIT\.$ convention of go vet and linters, then the provenance of the
+// file recorded by opts.
+func writeHeader(sb *strings.Builder, structs []parse.StructInfo, opts Options) error {
+	sb.WriteString("// Code generated by go-wrangler. DO NOT EDIT.\n")
+	if opts.Version != "" {
+		sb.WriteString("// Version: " + opts.Version + "\n")
 	}
-
-	imports := []string{"fmt"}
-	if needsStrconv {
-		imports = append(imports, "strconv")
+	if opts.Command != "" {
+		sb.WriteString("// Command: " + opts.Command + "\n")
 	}
-
-	// Function signature
-	sb.WriteString(fmt.Sprintf("func Validate%s(s *%s) error {\n", structInfo.Name, structInfo.Name))
-
-	// Validation logic
-	for _, tag := range structInfo.Tags {
-		if tag.Validate != nil {
-			if tag.FieldType == "int" {
-				if tag.Validate.Min != nil {
-					sb.WriteString(fmt.Sprintf("\tif s.%s < %d {\n\t\treturn fmt.Errorf(\"%s must be at least %d\")\n\t}\n", tag.FieldName, *tag.Validate.Min, tag.FieldName, *tag.Validate.Min))
-				}
-				if tag.Validate.Max != nil {
-					sb.WriteString(fmt.Sprintf("\tif s.%s > %d {\n\t\treturn fmt.Errorf(\"%s must be at most %d\")\n\t}\n", tag.FieldName, *tag.Validate.Max, tag.FieldName, *tag.Validate.Max))
-				}
-			} else {
-				// For non-int, parse and check
-				if tag.Validate.Min != nil {
-					sb.WriteString(fmt.Sprintf("\tif val, err := strconv.Atoi(s.%s); err != nil {\n\t\treturn fmt.Errorf(\"%s must be a valid integer\")\n\t} else if val < %d {\n\t\treturn fmt.Errorf(\"%s must be at least %d\")\n\t}\n", tag.FieldName, tag.FieldName, *tag.Validate.Min, tag.FieldName, *tag.Validate.Min))
-				}
-				if tag.Validate.Max != nil {
-					sb.WriteString(fmt.Sprintf("\tif val, err := strconv.Atoi(s.%s); err != nil {\n\t\treturn fmt.Errorf(\"%s must be a valid integer\")\n\t} else if val > %d {\n\t\treturn fmt.Errorf(\"%s must be at most %d\")\n\t}\n", tag.FieldName, tag.FieldName, *tag.Validate.Max, tag.FieldName, *tag.Validate.Max))
-				}
-			}
+	if opts.SourceHash {
+		// The parsed structs are hashed, so only changes to what the code is generated from count
+		data, err := json.Marshal(structs)
+		if err != nil {
+			return fmt.Errorf("hashing source structs: %w", err)
 		}
+		fmt.Fprintf(sb, "// Source hash: sha256:%x\n", sha256.Sum256(data))
 	}
-
-	sb.WriteString("\treturn nil\n}\n")
-
-	return sb.String(), imports
+	sb.WriteString("\n")
+	return nil
 }
 
-// GeneratePackage generates Go code for bind and validate functions for multiple structs
-func GeneratePackage(structs []parse.StructInfo, pkgName string) string {
-	var sb strings.Builder
-	sb.WriteString("package " + pkgName + "\n\n")
-
-	importSet := make(map[string]bool)
-	var functions []string
-
-	for _, s := range structs {
-		bindCode, bindImports := GenerateBindFunction(s)
-		functions = append(functions, bindCode)
-		for _, imp := range bindImports {
-			importSet[imp] = true
-		}
-
-		validateCode, validateImports := GenerateValidateFunction(s)
-		functions = append(functions, validateCode)
-		for _, imp := range validateImports {
-			importSet[imp] = true
-		}
+// writeImports writes the import declaration of the generated code, with the standard library
+// packages grouped before the other ones, each group sorted by path.
+func writeImports(sb *strings.Builder, imports []string) {
+	slices.Sort(imports)
+	imports = slices.Compact(imports)
+	if len(imports) == 0 {
+		return
 	}
 
-	if len(importSet) > 0 {
-		sb.WriteString("import (\n")
-		for imp := range importSet {
-			sb.WriteString("\t\"" + imp + "\"\n")
+	var std, other []string
+	for _, imp := range imports {
+		// Paths of the standard library have no dot in their first element
+		if first, _, _ := strings.Cut(imp, "/"); strings.Contains(first, ".") {
+			other = append(other, imp)
+		} else {
+			std = append(std, imp)
 		}
-		sb.WriteString(")\n\n")
 	}
 
-	for _, fn := range functions {
-		sb.WriteString(fn)
+	sb.WriteString("import (\n")
+	for _, imp := range std {
+		sb.WriteString("\t" + strconv.Quote(imp) + "\n")
+	}
+	if len(std) > 0 && len(other) > 0 {
 		sb.WriteString("\n")
 	}
+	for _, imp := range other {
+		sb.WriteString("\t" + strconv.Quote(imp) + "\n")
+	}
+	sb.WriteString(")\n\n")
+}
 
+// numberLines prefixes each line of code with its line number, to locate formatting errors.
+func numberLines(code string) string {
+	var sb strings.Builder
+	for i, line := range strings.Split(strings.TrimSuffix(code, "\n"), "\n") {
+		fmt.Fprintf(&sb, "%4d\t%s\n", i+1, line)
+	}
 	return sb.String()
 }
@@ -1,7 +1,11 @@
 package generator
 
 import (
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/pangobit/go-wrangler/internal/parse"
 )
@@ -30,41 +34,1135 @@ func TestGenerateBindFunction(t *testing.T) {
 				FieldName: "Age",
 				FieldType: "int",
 				Validate: &parse.ValidateTag{
-					Min: &[]int{18}[0],
-					Max: &[]int{120}[0],
+					Min: intBound(18),
+					Max: intBound(120),
 				},
 			},
 		},
 	}
 
-	bindCode, _ := GenerateBindFunction(structInfo)
-	validateCode, _ := GenerateValidateFunction(structInfo)
+	bindCode, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+	validateCode, _, err := GenerateValidateFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateValidateFunction() error = %v", err)
+	}
 	result := bindCode + validateCode
 
-	expected := `// Code generated by go-wrangler. DO NOT EDIT.
-
-func BindUser(r *http.Request, s *User) error {
-	s.Name = r.Header.Get("Name")
-	if s.Name == "" {
-		return fmt.Errorf("Name is required")
+	expected := `func BindUser(r *http.Request, s *User) error {
+	var errs wrangler.Errors
+	query := r.URL.Query()
+	if v := r.Header.Get("Name"); v != "" {
+		s.Name = v
+	} else {
+		errs = append(errs, &wrangler.FieldError{Field: "Name", Param: "Name", Source: "header", Rule: "required", Message: "Name is required"})
 	}
-	s.Email = r.URL.Query().Get("Email")
-	return nil
+	if v := query.Get("Email"); v != "" {
+		s.Email = v
+	}
+	return errs.Err()
 }
-// Code generated by go-wrangler. DO NOT EDIT.
-
 func ValidateUser(s *User) error {
+	var errs wrangler.Errors
 	if s.Age < 18 {
-		return fmt.Errorf("Age must be at least 18")
+		errs = append(errs, &wrangler.FieldError{Field: "Age", Rule: "min", Message: "Age must be at least 18"})
 	}
 	if s.Age > 120 {
-		return fmt.Errorf("Age must be at most 120")
+		errs = append(errs, &wrangler.FieldError{Field: "Age", Rule: "max", Message: "Age must be at most 120"})
 	}
-	return nil
+	return errs.Err()
 }
 `
 
 	if result != expected {
 		t.Errorf("GenerateBindFunction() = %v, want %v", result, expected)
 	}
-}
\ No newline at end of file
+}
+
+func TestGenerateBindFunctionBody(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "CreateUser",
+		Tags: []parse.TagInfo{
+			{
+				FieldName: "ID",
+				FieldType: "string",
+				Bind:      &parse.BindTag{Type: "path"},
+			},
+			{
+				FieldName: "Name",
+				FieldType: "string",
+				JSONName:  "name",
+				Bind:      &parse.BindTag{Type: "body", Required: true},
+			},
+			{
+				FieldName: "Age",
+				FieldType: "int",
+				Bind:      &parse.BindTag{Type: "body", MaxBytes: 512},
+			},
+		},
+	}
+
+	code, imports, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"Name *string `json:\"name\"`",
+		"Age *int `json:\"Age\"`",
+		"mime.ParseMediaType(r.Header.Get(\"Content-Type\"))",
+		"json.NewDecoder(http.MaxBytesReader(nil, r.Body, 512))",
+		"dec.DisallowUnknownFields()",
+		"if err := dec.Decode(&body); err != nil && err != io.EOF {",
+		"Rule: \"required\", Message: \"name is required\"})",
+		"s.Age = *body.Age",
+		"if v := r.PathValue(\"ID\"); v != \"\" {",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q", expected)
+		}
+	}
+
+	for _, imp := range []string{"encoding/json", "errors", "io", "mime", "strings"} {
+		if !slices.Contains(imports, imp) {
+			t.Errorf("imports = %v, missing %q", imports, imp)
+		}
+	}
+}
+
+func TestGenerateBindFunctionWholeBody(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "CreateUser",
+		Tags: []parse.TagInfo{
+			{
+				FieldName: "Payload",
+				FieldType: "UserPayload",
+				Bind:      &parse.BindTag{Type: "body", Whole: true, Required: true, AllowUnknown: true},
+			},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	if !strings.Contains(code, "if err := dec.Decode(&s.Payload); err == io.EOF {") {
+		t.Errorf("Expected whole body decode into s.Payload, got:\n%s", code)
+	}
+	if strings.Contains(code, "DisallowUnknownFields") {
+		t.Errorf("Expected unknown fields to be allowed, got:\n%s", code)
+	}
+	if strings.Count(code, "Payload is required") != 2 {
+		t.Errorf("Expected required checks for missing and empty body, got:\n%s", code)
+	}
+}
+
+func TestGenerateBindFunctionBodyErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []parse.TagInfo
+	}{
+		{
+			name: "whole mixed with keyed field",
+			tags: []parse.TagInfo{
+				{FieldName: "Payload", FieldType: "UserPayload", Bind: &parse.BindTag{Type: "body", Whole: true}},
+				{FieldName: "Name", FieldType: "string", Bind: &parse.BindTag{Type: "body"}},
+			},
+		},
+		{
+			name: "conflicting maxbytes",
+			tags: []parse.TagInfo{
+				{FieldName: "Name", FieldType: "string", Bind: &parse.BindTag{Type: "body", MaxBytes: 10}},
+				{FieldName: "Age", FieldType: "int", Bind: &parse.BindTag{Type: "body", MaxBytes: 20}},
+			},
+		},
+		{
+			name: "json name excluded",
+			tags: []parse.TagInfo{
+				{FieldName: "Name", FieldType: "string", JSONName: "-", Bind: &parse.BindTag{Type: "body"}},
+			},
+		},
+		{
+			name: "duplicate key",
+			tags: []parse.TagInfo{
+				{FieldName: "Billing.Name", FieldType: "string", JSONName: "name", Bind: &parse.BindTag{Type: "body"}},
+				{FieldName: "Shipping.Name", FieldType: "string", JSONName: "name", Bind: &parse.BindTag{Type: "body"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := GenerateBindFunction(parse.StructInfo{Name: "CreateUser", Tags: tt.tags}, Options{})
+			if err == nil {
+				t.Errorf("GenerateBindFunction() expected error but got none")
+			}
+		})
+	}
+}
+
+func TestGenerateBindFunctionNestedFields(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "ListUsers",
+		Tags: []parse.TagInfo{
+			{FieldName: "Page", FieldType: "int", Bind: &parse.BindTag{Type: "query", Name: "page"}},
+			{FieldName: "Filter.Status", FieldType: "*string", Bind: &parse.BindTag{Type: "query", Name: "filter[status]"}},
+			{FieldName: "Filter.Note", FieldType: "string", JSONName: "note", Bind: &parse.BindTag{Type: "body"}},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"Filter_Note *string `json:\"note\"`",
+		"if body.Filter_Note != nil {\n\t\ts.Filter.Note = *body.Filter_Note",
+		"if v := query.Get(\"filter[status]\"); v != \"\" {\n\t\ts.Filter.Status = new(string)\n\t\t*s.Filter.Status = v",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateBindFunctionForm(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Upload",
+		Tags: []parse.TagInfo{
+			{
+				FieldName: "Title",
+				FieldType: "string",
+				Bind:      &parse.BindTag{Type: "form", Required: true},
+			},
+			{
+				FieldName: "Tags",
+				FieldType: "[]string",
+				Bind:      &parse.BindTag{Type: "form"},
+			},
+			{
+				FieldName: "Avatar",
+				FieldType: "*multipart.FileHeader",
+				Bind:      &parse.BindTag{Type: "file", Required: true, MaxBytes: 1024, Accept: []string{"image/*", "application/pdf"}},
+			},
+			{
+				FieldName: "Docs",
+				FieldType: "[]*multipart.FileHeader",
+				Bind:      &parse.BindTag{Type: "file"},
+			},
+		},
+	}
+
+	code, imports, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"if err := r.ParseMultipartForm(33554432); err != nil {",
+		"} else if err := r.ParseForm(); err != nil {",
+		"if v := r.PostForm.Get(\"Title\"); v != \"\" {",
+		"if vals := r.PostForm[\"Tags\"]; len(vals) > 0 {\n\t\ts.Tags = vals",
+		"if fh.Size > 1024 {",
+		"!(strings.HasPrefix(mediaType, \"image/\") || mediaType == \"application/pdf\")",
+		"s.Avatar = r.MultipartForm.File[\"Avatar\"][0]",
+		"s.Docs = r.MultipartForm.File[\"Docs\"]",
+		"Rule: \"required\", Message: \"Avatar is required\"})",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q", expected)
+		}
+	}
+	if strings.Count(code, "ParseForm()") != 1 {
+		t.Errorf("Expected the form to be parsed once, got:\n%s", code)
+	}
+
+	for _, imp := range []string{"mime", "strings"} {
+		if !slices.Contains(imports, imp) {
+			t.Errorf("imports = %v, missing %q", imports, imp)
+		}
+	}
+}
+
+func TestGenerateBindFunctionFormErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []parse.TagInfo
+	}{
+		{
+			name: "file field with wrong type",
+			tags: []parse.TagInfo{
+				{FieldName: "Avatar", FieldType: "string", Bind: &parse.BindTag{Type: "file"}},
+			},
+		},
+		{
+			name: "form mixed with body",
+			tags: []parse.TagInfo{
+				{FieldName: "Title", FieldType: "string", Bind: &parse.BindTag{Type: "form"}},
+				{FieldName: "Name", FieldType: "string", Bind: &parse.BindTag{Type: "body"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := GenerateBindFunction(parse.StructInfo{Name: "Upload", Tags: tt.tags}, Options{})
+			if err == nil {
+				t.Errorf("GenerateBindFunction() expected error but got none")
+			}
+		})
+	}
+}
+
+func TestGenerateBindFunctionCookie(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Session",
+		Tags: []parse.TagInfo{
+			{
+				FieldName: "ID",
+				FieldType: "string",
+				Bind:      &parse.BindTag{Type: "cookie", Required: true},
+			},
+			{
+				FieldName: "CSRF",
+				FieldType: "*http.Cookie",
+				Bind:      &parse.BindTag{Type: "cookie"},
+			},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expected := `func BindSession(r *http.Request, s *Session) error {
+	var errs wrangler.Errors
+	if c, err := r.Cookie("ID"); err == nil && c.Value != "" {
+		s.ID = c.Value
+	} else {
+		errs = append(errs, &wrangler.FieldError{Field: "ID", Param: "ID", Source: "cookie", Rule: "required", Message: "ID is required"})
+	}
+	if c, err := r.Cookie("CSRF"); err == nil {
+		s.CSRF = c
+	}
+	return errs.Err()
+}
+`
+	if code != expected {
+		t.Errorf("GenerateBindFunction() = %v, want %v", code, expected)
+	}
+}
+
+func TestGenerateBindFunctionParamNames(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "GetUser",
+		Tags: []parse.TagInfo{
+			{
+				FieldName: "UserID",
+				FieldType: "string",
+				Bind:      &parse.BindTag{Type: "path", Name: "user_id", Required: true},
+			},
+			{
+				FieldName: "RequestID",
+				FieldType: "string",
+				Bind:      &parse.BindTag{Type: "header", Name: "X-Request-Id"},
+			},
+			{
+				FieldName: "Page",
+				FieldType: "int",
+				Bind:      &parse.BindTag{Type: "query"},
+			},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"if v := r.PathValue(\"user_id\"); v != \"\" {",
+		"Rule: \"required\", Message: \"user_id is required\"})",
+		"if v := r.Header.Get(\"X-Request-Id\"); v != \"\" {",
+		"if v := query.Get(\"Page\"); v != \"\" {",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q", expected)
+		}
+	}
+}
+
+func TestGenerateBindFunctionScalarTypes(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		expected  []string
+	}{
+		{"bool", []string{"strconv.ParseBool(v); err != nil {", "V must be a boolean", "s.V = val"}},
+		{"int", []string{"strconv.ParseInt(v, 10, 0); errors.Is(err, strconv.ErrRange) {", "V is out of range for int", "V must be a valid integer", "s.V = int(val)"}},
+		{"int8", []string{"strconv.ParseInt(v, 10, 8)", "V is out of range for int8", "s.V = int8(val)"}},
+		{"int64", []string{"strconv.ParseInt(v, 10, 64)", "s.V = val"}},
+		{"uint16", []string{"strconv.ParseUint(v, 10, 16)", "V must be a valid unsigned integer", "s.V = uint16(val)"}},
+		{"uint64", []string{"strconv.ParseUint(v, 10, 64)", "s.V = val"}},
+		{"float32", []string{"strconv.ParseFloat(v, 32)", "V must be a valid number", "s.V = float32(val)"}},
+		{"float64", []string{"strconv.ParseFloat(v, 64)", "V is out of range for float64"}},
+		{"[]byte", []string{"s.V = []byte(v)"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldType, func(t *testing.T) {
+			structInfo := parse.StructInfo{
+				Name: "Params",
+				Tags: []parse.TagInfo{
+					{FieldName: "V", FieldType: tt.fieldType, Bind: &parse.BindTag{Type: "query"}},
+				},
+			}
+
+			code, _, err := GenerateBindFunction(structInfo, Options{})
+			if err != nil {
+				t.Fatalf("GenerateBindFunction() error = %v", err)
+			}
+			for _, expected := range tt.expected {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateBindFunctionUnsupportedType(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Params",
+		Tags: []parse.TagInfo{
+			{FieldName: "V", FieldType: "complex128", Bind: &parse.BindTag{Type: "query"}},
+		},
+	}
+
+	_, _, err := GenerateBindFunction(structInfo, Options{})
+	if err == nil || !strings.Contains(err.Error(), "Params.V") {
+		t.Errorf("GenerateBindFunction() error = %v, want unsupported type error for Params.V", err)
+	}
+}
+
+func TestGenerateBindFunctionPointer(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Filter",
+		Tags: []parse.TagInfo{
+			{FieldName: "Page", FieldType: "*int", Bind: &parse.BindTag{Type: "query", Required: true}},
+			{FieldName: "Search", FieldType: "*string", Bind: &parse.BindTag{Type: "query"}},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"s.Page = new(int)\n\t\t\t*s.Page = int(val)",
+		"s.Search = new(string)\n\t\t*s.Search = v",
+		"Rule: \"required\", Message: \"Page is required\"})",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "== 0") {
+		t.Errorf("Expected required pointers to be checked for presence, got:\n%s", code)
+	}
+}
+
+func TestGenerateBindFunctionTextUnmarshaler(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Lookup",
+		Tags: []parse.TagInfo{
+			{FieldName: "ID", FieldType: "OrderID", Bind: &parse.BindTag{Type: "path", Name: "id"}, TextUnmarshaler: true},
+			{FieldName: "IP", FieldType: "*netip.Addr", Bind: &parse.BindTag{Type: "header", Name: "X-IP"}, TypeImports: []string{"net/netip"}, TextUnmarshaler: true},
+			{FieldName: "Nets", FieldType: "[]netip.Prefix", Bind: &parse.BindTag{Type: "query", Name: "net"}, TypeImports: []string{"net/netip"}, TextUnmarshaler: true},
+			{FieldName: "Since", FieldType: "time.Time", Bind: &parse.BindTag{Type: "body", Name: "since"}, TypeImports: []string{"time"}},
+		},
+	}
+
+	code, imports, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"\t\tvar val OrderID\n\t\tif err := val.UnmarshalText([]byte(v)); err != nil {\n" +
+			"\t\t\terrs = append(errs, &wrangler.FieldError{Field: \"ID\", Param: \"id\", Source: \"path\", Rule: \"type\", Message: \"id is invalid: \" + err.Error(), Err: err})\n" +
+			"\t\t} else {\n\t\t\ts.ID = val\n\t\t}",
+		"\t\tvar val netip.Addr\n",
+		"s.IP = new(netip.Addr)\n\t\t\t*s.IP = val",
+		"\t\t\tvar val netip.Prefix\n\t\t\tif err := val.UnmarshalText([]byte(v)); err != nil {",
+		"items = append(items, val)",
+		"Since *time.Time `json:\"since\"`",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	for _, imp := range []string{"net/netip", "time"} {
+		if !slices.Contains(imports, imp) {
+			t.Errorf("imports = %v, missing %q", imports, imp)
+		}
+	}
+
+	structInfo.Tags[0].Bind.Default = &[]string{"ord_1"}[0]
+	if _, _, err := GenerateBindFunction(structInfo, Options{}); err == nil || err.Error() != "Lookup.ID: fields bound with UnmarshalText can't have a default" {
+		t.Errorf("GenerateBindFunction() error = %v, want a default error", err)
+	}
+}
+
+func TestGenerateBindFunctionNamedTypes(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Search",
+		Tags: []parse.TagInfo{
+			{FieldName: "User", FieldType: "UserID", ElemType: "UserID", UnderlyingType: "int", Bind: &parse.BindTag{Type: "path", Name: "user"}},
+			{FieldName: "Manager", FieldType: "*UserID", ElemType: "UserID", UnderlyingType: "*int", Bind: &parse.BindTag{Type: "query", Name: "manager"}},
+			{FieldName: "Friends", FieldType: "[]UserID", ElemType: "UserID", UnderlyingType: "[]int", Bind: &parse.BindTag{Type: "query", Name: "friend", Default: &[]string{"1|2"}[0]}},
+			{FieldName: "Status", FieldType: "Status", ElemType: "Status", UnderlyingType: "string", Bind: &parse.BindTag{Type: "query", Name: "status"}},
+			{FieldName: "Tags", FieldType: "Tags", ElemType: "string", UnderlyingType: "[]string", Bind: &parse.BindTag{Type: "query", Name: "tag"}},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"if val, err := strconv.ParseInt(v, 10, 0);",
+		"s.User = UserID(int(val))",
+		"s.Manager = new(UserID)\n\t\t\t*s.Manager = UserID(int(val))",
+		"items := make([]UserID, 0, len(vals))",
+		"items = append(items, UserID(int(val)))",
+		"s.Friends = []UserID{1, 2}",
+		"s.Status = Status(v)",
+		"s.Tags = vals",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateBindFunctionTimes(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Events",
+		Tags: []parse.TagInfo{
+			{FieldName: "Since", FieldType: "time.Time", Bind: &parse.BindTag{Type: "query", Name: "since"}, TextUnmarshaler: true},
+			{FieldName: "Day", FieldType: "*time.Time", Bind: &parse.BindTag{Type: "query", Name: "day", Layout: "date"}, TextUnmarshaler: true},
+			{FieldName: "At", FieldType: "time.Time", Bind: &parse.BindTag{Type: "header", Name: "X-At", Layout: "unixmilli"}, TextUnmarshaler: true},
+			{FieldName: "Days", FieldType: "[]time.Time", Bind: &parse.BindTag{Type: "query", Name: "days", Layout: "02/01/2006"}, TextUnmarshaler: true},
+			{FieldName: "Timeout", FieldType: "time.Duration", Bind: &parse.BindTag{Type: "query", Name: "timeout", Default: &[]string{"1m30s"}[0]}},
+		},
+	}
+
+	code, imports, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"\t\tif val, err := time.Parse(time.RFC3339, v); err != nil {\n" +
+			"\t\t\terrs = append(errs, &wrangler.FieldError{Field: \"Since\", Param: \"since\", Source: \"query\", Rule: \"type\", Message: \"since must be a time formatted as 2006-01-02T15:04:05Z07:00\", Err: err})\n" +
+			"\t\t} else {\n\t\t\ts.Since = val\n\t\t}",
+		"if val, err := time.Parse(time.DateOnly, v); err != nil {",
+		"s.Day = new(time.Time)\n\t\t\t*s.Day = val",
+		"\t\tif val, err := strconv.ParseInt(v, 10, 64); err != nil {\n" +
+			"\t\t\terrs = append(errs, &wrangler.FieldError{Field: \"At\", Param: \"X-At\", Source: \"header\", Rule: \"type\", Message: \"X-At must be a Unix time in milliseconds\"})\n" +
+			"\t\t} else {\n\t\t\ts.At = time.UnixMilli(val)\n\t\t}",
+		"if val, err := time.Parse(\"02/01/2006\", v); err != nil {",
+		"Message: \"days must be a time formatted as 02/01/2006\"",
+		"\t\tif val, err := time.ParseDuration(v); err != nil {\n" +
+			"\t\t\terrs = append(errs, &wrangler.FieldError{Field: \"Timeout\", Param: \"timeout\", Source: \"query\", Rule: \"type\", Message: \"timeout must be a duration such as 1h30m or 500ms\", Err: err})",
+		"\t} else {\n\t\ts.Timeout = 90000000000\n\t}",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "UnmarshalText") {
+		t.Errorf("Expected times to be parsed with their layout, got:\n%s", code)
+	}
+	if !slices.Contains(imports, "time") {
+		t.Errorf("imports = %v, missing time", imports)
+	}
+}
+
+func TestGenerateBindFunctionTimeErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		bind      parse.BindTag
+		wantErr   string
+	}{
+		{"unknown layout", "time.Time", parse.BindTag{Type: "query", Layout: "iso"}, `invalid layout "iso": not a built-in layout, nor a Go layout`},
+		{"layout on other types", "string", parse.BindTag{Type: "query", Layout: "date"}, "layout only applies to time.Time fields"},
+		{"time default", "time.Time", parse.BindTag{Type: "query", Default: &[]string{"2024-01-01"}[0]}, "time.Time fields can't have a default"},
+		{"invalid duration default", "time.Duration", parse.BindTag{Type: "query", Default: &[]string{"5 minutes"}[0]}, `default "5 minutes" must be a duration such as 1h30m or 500ms`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			structInfo := parse.StructInfo{
+				Name: "Params",
+				Tags: []parse.TagInfo{{FieldName: "V", FieldType: tt.fieldType, Bind: &tt.bind}},
+			}
+			_, _, err := GenerateBindFunction(structInfo, Options{})
+			if err == nil || err.Error() != "Params.V: "+tt.wantErr {
+				t.Errorf("GenerateBindFunction() error = %v, want %q", err, "Params.V: "+tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateBindFunctionMissingErrors(t *testing.T) {
+	// The parser gives file and body fields no default, but a default reaching them still fails
+	tests := []struct {
+		name      string
+		fieldType string
+		bind      parse.BindTag
+		wantErr   string
+	}{
+		{"file default", "*multipart.FileHeader", parse.BindTag{Type: "file", Default: &[]string{"a.txt"}[0]}, `Params.V: default unsupported field type "multipart.FileHeader"`},
+		{"body default", "time.Time", parse.BindTag{Type: "body", Default: &[]string{"2024-01-01"}[0]}, "Params.V: time.Time fields can't have a default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			structInfo := parse.StructInfo{
+				Name: "Params",
+				Tags: []parse.TagInfo{{FieldName: "V", FieldType: tt.fieldType, Bind: &tt.bind}},
+			}
+			_, _, err := GenerateBindFunction(structInfo, Options{})
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("GenerateBindFunction() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateBindFunctionSliceErrors(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Params",
+		Tags: []parse.TagInfo{
+			{FieldName: "IDs", FieldType: "[]int", Bind: &parse.BindTag{Type: "path"}},
+		},
+	}
+
+	_, _, err := GenerateBindFunction(structInfo, Options{})
+	if err == nil || !strings.Contains(err.Error(), "slice fields can't be bound from path") {
+		t.Errorf("GenerateBindFunction() error = %v, want slice error", err)
+	}
+}
+
+func TestGenerateBindFunctionDefaults(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "ListParams",
+		Tags: []parse.TagInfo{
+			{FieldName: "Limit", FieldType: "int", Bind: &parse.BindTag{Type: "query", Default: &[]string{"020"}[0]}},
+			{FieldName: "Sort", FieldType: "string", Bind: &parse.BindTag{Type: "query", Default: &[]string{"created_at"}[0]}},
+			{FieldName: "Ratio", FieldType: "*float32", Bind: &parse.BindTag{Type: "header", Default: &[]string{"0.5"}[0]}},
+			{FieldName: "Fields", FieldType: "[]string", Bind: &parse.BindTag{Type: "query", Default: &[]string{"id|name"}[0]}},
+			{FieldName: "Theme", FieldType: "string", Bind: &parse.BindTag{Type: "cookie", Default: &[]string{"light"}[0]}},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"\t} else {\n\t\ts.Limit = 20\n\t}",
+		"\t} else {\n\t\ts.Sort = \"created_at\"\n\t}",
+		"\t} else {\n\t\ts.Ratio = new(float32)\n\t\t*s.Ratio = 0.5\n\t}",
+		"\t} else {\n\t\ts.Fields = []string{\"id\", \"name\"}\n\t}",
+		"\t} else {\n\t\ts.Theme = \"light\"\n\t}",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+}
+
+func TestGenerateBindFunctionDefaultErrors(t *testing.T) {
+	tests := []struct {
+		fieldType string
+		def       string
+		wantErr   string
+	}{
+		{"int", "abc", `default "abc" must be a valid integer`},
+		{"int8", "300", `default "300" is out of range for int8`},
+		{"uint", "-1", `default "-1" must be a valid unsigned integer`},
+		{"bool", "yes", `default "yes" must be a boolean`},
+		{"float64", "NaN", `default "NaN" must be a valid number`},
+		{"[]int", "1|x", `default "x" must be a valid integer`},
+		{"*http.Cookie", "x", "*http.Cookie fields can't have a default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.fieldType, func(t *testing.T) {
+			bindType := "query"
+			if tt.fieldType == "*http.Cookie" {
+				bindType = "cookie"
+			}
+			structInfo := parse.StructInfo{
+				Name: "Params",
+				Tags: []parse.TagInfo{
+					{FieldName: "V", FieldType: tt.fieldType, Bind: &parse.BindTag{Type: bindType, Default: &tt.def}},
+				},
+			}
+
+			_, _, err := GenerateBindFunction(structInfo, Options{})
+			if err == nil || err.Error() != "Params.V: "+tt.wantErr {
+				t.Errorf("GenerateBindFunction() error = %v, want %q", err, "Params.V: "+tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateBindFunctionFailFast(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Params",
+		Tags: []parse.TagInfo{
+			{FieldName: "Page", FieldType: "int", Bind: &parse.BindTag{Type: "query", Name: "page", Required: true}},
+		},
+	}
+
+	code, _, err := GenerateBindFunction(structInfo, Options{FailFast: true})
+	if err != nil {
+		t.Fatalf("GenerateBindFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"return wrangler.Errors{&wrangler.FieldError{Field: \"Page\", Param: \"page\", Source: \"query\", Rule: \"range\", Message: \"page is out of range for int\"}}",
+		"return wrangler.Errors{&wrangler.FieldError{Field: \"Page\", Param: \"page\", Source: \"query\", Rule: \"type\", Message: \"page must be a valid integer\"}}",
+		"return wrangler.Errors{&wrangler.FieldError{Field: \"Page\", Param: \"page\", Source: \"query\", Rule: \"required\", Message: \"page is required\"}}",
+		"\treturn nil\n}",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "errs") {
+		t.Errorf("Expected no error collection in fail-fast mode, got:\n%s", code)
+	}
+}
+
+func TestGenerateValidateFunctionStringRules(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Signup",
+		Tags: []parse.TagInfo{
+			{FieldName: "Username", FieldType: "string", Validate: &parse.ValidateTag{MinLen: &[]int{3}[0], MaxLen: &[]int{20}[0], Pattern: "^[a-z0-9_]+$"}},
+			{FieldName: "Country", FieldType: "*string", Validate: &parse.ValidateTag{Len: &[]int{2}[0]}},
+			{FieldName: "Plan", FieldType: "string", Validate: &parse.ValidateTag{OneOf: []string{"free", "pro"}}},
+			{FieldName: "Seats", FieldType: "int", Validate: &parse.ValidateTag{OneOf: []string{"1", "5", "10"}}},
+			{FieldName: "Email", FieldType: "string", Validate: &parse.ValidateTag{Format: "email"}},
+		},
+	}
+
+	code, imports, err := GenerateValidateFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateValidateFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"var wrangler_Signup_Username_pattern = regexp.MustCompile(\"^[a-z0-9_]+$\")\n\nfunc ValidateSignup(s *Signup) error {",
+		"if utf8.RuneCountInString(s.Username) < 3 {",
+		"Rule: \"minlen\", Message: \"Username must be at least 3 characters long\"",
+		"if utf8.RuneCountInString(s.Username) > 20 {",
+		"if !wrangler_Signup_Username_pattern.MatchString(s.Username) {",
+		"Rule: \"pattern\", Message: \"Username must match the pattern ^[a-z0-9_]+$\"",
+		"if s.Country != nil {\n\t\tif utf8.RuneCountInString(*s.Country) != 2 {",
+		"if s.Plan != \"free\" && s.Plan != \"pro\" {",
+		"Rule: \"oneof\", Message: \"Plan must be one of free, pro\"",
+		"if s.Seats != 1 && s.Seats != 5 && s.Seats != 10 {",
+		"if !wrangler.IsEmail(s.Email) {",
+		"Rule: \"format\", Message: \"Email must be a valid email address\"",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if strings.Count(code, "regexp.MustCompile") != 1 {
+		t.Errorf("Expected the pattern to be compiled once, got:\n%s", code)
+	}
+	for _, imp := range []string{"regexp", "unicode/utf8"} {
+		if !slices.Contains(imports, imp) {
+			t.Errorf("imports = %v, missing %q", imports, imp)
+		}
+	}
+}
+
+func TestPatternVar(t *testing.T) {
+	tests := []struct {
+		structName string
+		fieldName  string
+		expected   string
+	}{
+		{"Signup", "Username", "wrangler_Signup_Username_pattern"},
+		{"AB", "C", "wrangler_AB_C_pattern"},
+		{"A", "BC", "wrangler_A_BC_pattern"},
+		{"Search", "F.X", "wrangler_Search_F_X_pattern"},
+		{"Search", "F_X", "wrangler_Search_F_0X_pattern"},
+		{"A_", "B", "wrangler_A_0_B_pattern"},
+		{"A", "_B", "wrangler_A__0B_pattern"},
+	}
+
+	seen := make(map[string]bool)
+	for _, tt := range tests {
+		got := patternVar(tt.structName, tt.fieldName)
+		if got != tt.expected {
+			t.Errorf("patternVar(%q, %q) = %q, want %q", tt.structName, tt.fieldName, got, tt.expected)
+		}
+		if seen[got] {
+			t.Errorf("patternVar(%q, %q) = %q, already used by another field", tt.structName, tt.fieldName, got)
+		}
+		seen[got] = true
+	}
+}
+
+func TestGenerateValidateFunctionRuleErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		fieldType string
+		validate  *parse.ValidateTag
+		wantErr   string
+	}{
+		{"min on string", "string", &parse.ValidateTag{Min: intBound(1)}, "min doesn't apply to string fields, use minlen to bound their length"},
+		{"max on string pointer", "*string", &parse.ValidateTag{Max: intBound(1)}, "max doesn't apply to string fields, use maxlen to bound their length"},
+		{"maxlen on int", "int", &parse.ValidateTag{MaxLen: &[]int{1}[0]}, "maxlen doesn't apply to int fields"},
+		{"pattern on slice", "[]string", &parse.ValidateTag{Pattern: "^a$"}, "pattern doesn't apply to []string fields"},
+		{"min on bool", "bool", &parse.ValidateTag{Min: intBound(1)}, "min doesn't apply to bool fields"},
+		{"invalid pattern", "string", &parse.ValidateTag{Pattern: "[a-"}, "invalid pattern: error parsing regexp: missing closing ]: `[a-`"},
+		{"oneof value of wrong type", "uint8", &parse.ValidateTag{OneOf: []string{"1", "256"}}, `oneof value "256" is out of range for uint8`},
+		{"unknown format", "string", &parse.ValidateTag{Format: "phone"}, `unknown format "phone"`},
+		{"format on int", "int", &parse.ValidateTag{Format: "email"}, "format doesn't apply to int fields"},
+		{"float bound on int", "int", &parse.ValidateTag{Min: &parse.Bound{Kind: parse.FloatBound, Float: 0.5, Raw: "0.5"}}, `min "0.5" must be a valid integer`},
+		{"bound out of range", "int8", &parse.ValidateTag{Max: intBound(200)}, `max "200" is out of range for int8`},
+		{"negative bound on uint", "uint", &parse.ValidateTag{Gt: intBound(-1)}, `gt "-1" must be a valid unsigned integer`},
+		{"int bound on duration", "time.Duration", &parse.ValidateTag{Max: intBound(5)}, `max "5" must be a duration, e.g. 1m30s`},
+		{"duration bound on time", "time.Time", &parse.ValidateTag{Lt: &parse.Bound{Kind: parse.DurationBound, Duration: time.Hour, Raw: "1h"}}, `lt "1h" must be an RFC 3339 time or date`},
+		{"gt on string", "string", &parse.ValidateTag{Gt: intBound(1)}, "gt doesn't apply to string fields"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			structInfo := parse.StructInfo{
+				Name: "Params",
+				Tags: []parse.TagInfo{{FieldName: "V", FieldType: tt.fieldType, Validate: tt.validate}},
+			}
+
+			_, _, err := GenerateValidateFunction(structInfo, Options{})
+			if err == nil || err.Error() != "Params.V: "+tt.wantErr {
+				t.Errorf("GenerateValidateFunction() error = %v, want %q", err, "Params.V: "+tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateValidateFunctionCrossFieldRules(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Query",
+		Tags: []parse.TagInfo{
+			{FieldName: "To", FieldType: "*time.Time", Validate: &parse.ValidateTag{GtField: "From"}},
+			{FieldName: "Max", FieldType: "int", Validate: &parse.ValidateTag{LteField: "Limit", NeField: "Min"}},
+			{FieldName: "Depth", FieldType: "*int", Bind: &parse.BindTag{Type: "query", Name: "depth"}, Validate: &parse.ValidateTag{
+				RequiredIf: []parse.Condition{{Field: "Mode", Value: "advanced"}, {Field: "Level", Value: "2"}},
+			}},
+			{FieldName: "Email", FieldType: "string", Validate: &parse.ValidateTag{RequiredWithout: []string{"Phone", "Tags"}}},
+			{FieldName: "Phone", FieldType: "string", Validate: &parse.ValidateTag{RequiredWith: []string{"Verify"}, ExcludedWith: []string{"Email"}}},
+		},
+		FieldTypes: map[string]string{
+			"From": "time.Time", "To": "*time.Time", "Min": "int", "Max": "int", "Limit": "*int", "Mode": "string",
+			"Level": "*uint8", "Depth": "*int", "Email": "string", "Phone": "string", "Tags": "[]string", "Verify": "bool",
+		},
+	}
+
+	code, _, err := GenerateValidateFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateValidateFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"if s.To != nil {\n\t\tif !s.To.After(s.From) {",
+		"Rule: \"gtfield\", Message: \"To must be after From\"",
+		"if s.Limit != nil && s.Max > *s.Limit {",
+		"Message: \"Max must be less than or equal to Limit\"",
+		"if s.Max == s.Min {",
+		"if s.Depth == nil && s.Mode == \"advanced\" && s.Level != nil && *s.Level == 2 {",
+		"Param: \"depth\", Source: \"query\", Rule: \"required_if\", Message: \"Depth is required when Mode is advanced and Level is 2\"",
+		"if s.Email == \"\" && (s.Phone == \"\" || len(s.Tags) == 0) {",
+		"Message: \"Email is required when Phone or Tags is not set\"",
+		"if s.Phone == \"\" && s.Verify {",
+		"if s.Phone != \"\" && s.Email != \"\" {",
+		"Rule: \"excluded_with\", Message: \"Phone must not be set when Email is set\"",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if strings.Contains(code, "if s.Depth != nil {") {
+		t.Errorf("Expected no empty pointer check for a field without value rules, got:\n%s", code)
+	}
+}
+
+func TestGenerateValidateFunctionCrossFieldErrors(t *testing.T) {
+	fieldTypes := map[string]string{"V": "int", "Count": "*int", "Name": "string", "Meta": "Metadata", "At": "time.Time"}
+	tests := []struct {
+		name      string
+		fieldType string
+		validate  *parse.ValidateTag
+		wantErr   string
+	}{
+		{"unknown field", "int", &parse.ValidateTag{GtField: "Limit"}, "gtfield refers to unknown field Limit"},
+		{"same field", "int", &parse.ValidateTag{EqField: "V"}, "eqfield refers to the field itself"},
+		{"different types", "int", &parse.ValidateTag{LtField: "Name"}, "ltfield can't compare int with string field Name"},
+		{"order of strings", "string", &parse.ValidateTag{GtField: "Name"}, "gtfield doesn't apply to string fields"},
+		{"presence of the field", "Metadata", &parse.ValidateTag{RequiredWith: []string{"Name"}}, "can't tell whether Metadata field V is set"},
+		{"unknown required_with field", "int", &parse.ValidateTag{RequiredWith: []string{"Limit"}}, "required_with refers to unknown field Limit"},
+		{"required_if value of wrong type", "int", &parse.ValidateTag{RequiredIf: []parse.Condition{{Field: "Count", Value: "many"}}}, `required_if value of Count "many" must be a valid integer`},
+		{"presence of struct", "int", &parse.ValidateTag{ExcludedWith: []string{"Meta"}}, "excluded_with can't tell whether Metadata field Meta is set"},
+		{"time compared with int", "time.Time", &parse.ValidateTag{GteField: "Count"}, "gtefield can't compare time.Time with *int field Count"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			structInfo := parse.StructInfo{
+				Name:       "Params",
+				Tags:       []parse.TagInfo{{FieldName: "V", FieldType: tt.fieldType, Validate: tt.validate}},
+				FieldTypes: fieldTypes,
+			}
+
+			_, _, err := GenerateValidateFunction(structInfo, Options{})
+			if err == nil || err.Error() != "Params.V: "+tt.wantErr {
+				t.Errorf("GenerateValidateFunction() error = %v, want %q", err, "Params.V: "+tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGenerateValidateFunctionHooks(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name:               "Order",
+		Tags:               []parse.TagInfo{{FieldName: "Qty", FieldType: "int", Validate: &parse.ValidateTag{Min: intBound(1)}}},
+		HasValidate:        true,
+		HasValidateContext: true,
+	}
+
+	tests := []struct {
+		name             string
+		opts             Options
+		expectedContains []string
+	}{
+		{
+			name: "collect",
+			expectedContains: []string{
+				"func ValidateOrder(s *Order) error {\n\treturn ValidateOrderContext(context.Background(), s)\n}",
+				"func ValidateOrderContext(ctx context.Context, s *Order) error {",
+				"\terrs = errs.Append(s.Validate())\n\terrs = errs.Append(s.ValidateContext(ctx))\n\treturn errs.Err()\n}",
+			},
+		},
+		{
+			name: "fail fast",
+			opts: Options{FailFast: true},
+			expectedContains: []string{
+				"if err := s.Validate(); err != nil {\n\t\treturn wrangler.Errors{}.Append(err).Err()\n\t}",
+				"if err := s.ValidateContext(ctx); err != nil {",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, imports, err := GenerateValidateFunction(structInfo, tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateValidateFunction() error = %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+				}
+			}
+			for _, imp := range []string{"context", wranglerImport} {
+				if !slices.Contains(imports, imp) {
+					t.Errorf("imports = %v, missing %q", imports, imp)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateValidateFunctionNested(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Order",
+		Nested: []parse.NestedField{
+			{FieldName: "Address", Struct: "Address"},
+			{FieldName: "Billing", Struct: "Address", Pointer: true},
+			{FieldName: "Items", Struct: "Item", Kind: parse.NestedSlice, Context: true},
+			{FieldName: "Parts", Struct: "Item", Kind: parse.NestedSlice, Pointer: true, Context: true},
+			{FieldName: "ByCode", Struct: "Address", Kind: parse.NestedMap, KeyType: "string"},
+			{FieldName: "ByKey", Struct: "Address", Kind: parse.NestedMap, Pointer: true, KeyType: "Key"},
+		},
+		Context: true,
+	}
+
+	tests := []struct {
+		name             string
+		opts             Options
+		expectedContains []string
+		expectedImports  []string
+	}{
+		{
+			name: "collect",
+			expectedContains: []string{
+				"func ValidateOrderContext(ctx context.Context, s *Order) error {",
+				"	errs = errs.Nest(\"Address\", ValidateAddress(&s.Address))\n",
+				"	if s.Billing != nil {\n\t\terrs = errs.Nest(\"Billing\", ValidateAddress(s.Billing))\n\t}",
+				"	for i := range s.Items {\n\t\terrs = errs.Nest(fmt.Sprintf(\"Items[%d]\", i), ValidateItemContext(ctx, &s.Items[i]))\n\t}",
+				"	for i, v := range s.Parts {\n\t\tif v != nil {\n\t\t\terrs = errs.Nest(fmt.Sprintf(\"Parts[%d]\", i), ValidateItemContext(ctx, v))",
+				"	for _, k := range slices.Sorted(maps.Keys(s.ByCode)) {\n\t\tv := s.ByCode[k]\n\t\terrs = errs.Nest(fmt.Sprintf(\"ByCode[%v]\", k), ValidateAddress(&v))",
+				"	for k, v := range s.ByKey {\n\t\tif v != nil {\n\t\t\terrs = errs.Nest(fmt.Sprintf(\"ByKey[%v]\", k), ValidateAddress(v))",
+			},
+			expectedImports: []string{"context", "fmt", "maps", "slices", wranglerImport},
+		},
+		{
+			name: "fail fast",
+			opts: Options{FailFast: true},
+			expectedContains: []string{
+				"	if err := ValidateAddress(&s.Address); err != nil {\n\t\treturn wrangler.Errors{}.Nest(\"Address\", err).Err()\n\t}",
+				"		if err := ValidateItemContext(ctx, &s.Items[i]); err != nil {\n\t\t\treturn wrangler.Errors{}.Nest(fmt.Sprintf(\"Items[%d]\", i), err).Err()\n\t\t}",
+			},
+			expectedImports: []string{"context", "fmt", "maps", "slices", wranglerImport},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, imports, err := GenerateValidateFunction(structInfo, tt.opts)
+			if err != nil {
+				t.Fatalf("GenerateValidateFunction() error = %v", err)
+			}
+			for _, expected := range tt.expectedContains {
+				if !strings.Contains(code, expected) {
+					t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+				}
+			}
+			for _, imp := range tt.expectedImports {
+				if !slices.Contains(imports, imp) {
+					t.Errorf("imports = %v, missing %q", imports, imp)
+				}
+			}
+		})
+	}
+}
+
+func TestGenerateValidateFunctionCustomValidators(t *testing.T) {
+	sku := parse.Validator{Name: "sku", Func: "sku.Validate", ImportPath: "example.com/app/sku", ParamType: "string"}
+	slug := parse.Validator{Name: "slug", Func: "validateSlug", ParamType: "string"}
+	structInfo := parse.StructInfo{
+		Name: "Item",
+		Tags: []parse.TagInfo{
+			{FieldName: "SKU", FieldType: "*string", Validate: &parse.ValidateTag{Validators: []parse.Validator{sku}}},
+			{FieldName: "Tenant", FieldType: "string", Bind: &parse.BindTag{Type: "query", Name: "tenant"}, Validate: &parse.ValidateTag{Validators: []parse.Validator{slug}}},
+		},
+	}
+
+	code, imports, err := GenerateValidateFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateValidateFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"if s.SKU != nil {\n\t\tif err := sku.Validate(*s.SKU); err != nil {",
+		"errs = append(errs, &wrangler.FieldError{Field: \"SKU\", Rule: \"sku\", Message: \"SKU: \" + err.Error(), Err: err})",
+		"if err := validateSlug(s.Tenant); err != nil {",
+		"Param: \"tenant\", Source: \"query\", Rule: \"slug\"",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if !slices.Contains(imports, "example.com/app/sku") {
+		t.Errorf("imports = %v, missing the validator package", imports)
+	}
+
+	structInfo.Tags[1].FieldType = "int"
+	if _, _, err := GenerateValidateFunction(structInfo, Options{}); err == nil || err.Error() != "Item.Tenant: validator slug takes string values, not int" {
+		t.Errorf("GenerateValidateFunction() error = %v, want a type mismatch", err)
+	}
+}
+
+// intBound returns the bound parsed from an integer bound value
+func intBound(n int64) *parse.Bound {
+	return &parse.Bound{Kind: parse.IntBound, Int: n, Raw: strconv.FormatInt(n, 10)}
+}
+
+func TestGenerateValidateFunctionBounds(t *testing.T) {
+	structInfo := parse.StructInfo{
+		Name: "Query",
+		Tags: []parse.TagInfo{
+			{FieldName: "Ratio", FieldType: "float64", Validate: &parse.ValidateTag{
+				Gt:  &parse.Bound{Kind: parse.FloatBound, Float: 0, Raw: "0"},
+				Max: &parse.Bound{Kind: parse.FloatBound, Float: 0.5, Raw: "0.5"},
+			}},
+			{FieldName: "Count", FieldType: "uint8", Validate: &parse.ValidateTag{Lt: intBound(10)}},
+			{FieldName: "Timeout", FieldType: "time.Duration", Validate: &parse.ValidateTag{
+				Min: &parse.Bound{Kind: parse.DurationBound, Duration: 1500 * time.Millisecond, Raw: "1.5s"},
+				Lt:  &parse.Bound{Kind: parse.DurationBound, Duration: 24 * time.Hour, Raw: "24h"},
+			}},
+			{FieldName: "Since", FieldType: "*time.Time", Validate: &parse.ValidateTag{
+				Gt: &parse.Bound{Kind: parse.TimeBound, Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Raw: "2024-01-01"},
+			}},
+			{FieldName: "IDs", FieldType: "[]string", Validate: &parse.ValidateTag{Gt: intBound(0)}},
+		},
+	}
+
+	code, imports, err := GenerateValidateFunction(structInfo, Options{})
+	if err != nil {
+		t.Fatalf("GenerateValidateFunction() error = %v", err)
+	}
+
+	expectedContains := []string{
+		"if s.Ratio > 0.5 {",
+		"if s.Ratio <= 0 {",
+		"Rule: \"gt\", Message: \"Ratio must be greater than 0\"",
+		"if s.Count >= 10 {",
+		"Message: \"Count must be less than 10\"",
+		"if s.Timeout < 1500 * time.Millisecond {",
+		"if s.Timeout >= 24 * time.Hour {",
+		"if s.Since != nil {\n\t\tif !s.Since.After(time.Unix(1704067200, 0)) {",
+		"Message: \"Since must be after 2024-01-01\"",
+		"if len(s.IDs) <= 0 {",
+		"Message: \"IDs must contain more than 0 items\"",
+	}
+	for _, expected := range expectedContains {
+		if !strings.Contains(code, expected) {
+			t.Errorf("Generated code does not contain expected string: %q\n%s", expected, code)
+		}
+	}
+	if !slices.Contains(imports, "time") {
+		t.Errorf("imports = %v, missing \"time\"", imports)
+	}
+}
@@ -0,0 +1,140 @@
+package generator
+
+import (
+	"flag"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/pangobit/go-wrangler/internal/parse"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of TestGeneratePackageGolden")
+
+// TestGeneratePackageGolden generates the packages of testdata/golden, and compares the code with
+// the golden file of the same name. Run go test -run TestGeneratePackageGolden -update to rewrite
+// the golden files after changing the generated code, and review their diff.
+func TestGeneratePackageGolden(t *testing.T) {
+	tests := []struct {
+		name string
+		opts Options
+	}{
+		{"bind", Options{}},
+		{"body", Options{}},
+		{"form", Options{}},
+		{"validate", Options{}},
+		{"nested", Options{}},
+		{"failfast", Options{FailFast: true}},
+		{"provenance", Options{Version: "v1.2.3", Command: "go-wrangler --provenance ./api", SourceHash: true}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The fail-fast and provenance variants generate the package of another case
+			dir := filepath.Join("testdata", "golden", tt.name)
+			switch tt.name {
+			case "failfast":
+				dir = filepath.Join("testdata", "golden", "validate")
+			case "provenance":
+				dir = filepath.Join("testdata", "golden", "form")
+			}
+			structs, pkgName, diags, err := parse.ParsePackage(dir)
+			if err != nil {
+				t.Fatalf("ParsePackage() error = %v", err)
+			}
+			if len(diags) > 0 {
+				t.Fatalf("ParsePackage() diagnostics = %v", diags)
+			}
+			code, err := GeneratePackage(structs, pkgName, tt.opts)
+			if err != nil {
+				t.Fatalf("GeneratePackage() error = %v", err)
+			}
+
+			// Generating the same structs again gives the same bytes
+			for range 5 {
+				again, err := GeneratePackage(structs, pkgName, tt.opts)
+				if err != nil {
+					t.Fatalf("GeneratePackage() error = %v", err)
+				}
+				if again != code {
+					t.Fatalf("GeneratePackage() isn't deterministic, got:\n%s\nthen:\n%s", code, again)
+				}
+			}
+
+			golden := filepath.Join("testdata", "golden", tt.name+".golden")
+			if *update {
+				if err := os.WriteFile(golden, []byte(code), 0644); err != nil {
+					t.Fatalf("Failed to write golden file: %v", err)
+				}
+				return
+			}
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("Failed to read golden file: %v", err)
+			}
+			if code != string(want) {
+				t.Errorf("GeneratePackage() differs from %s, run go test -run TestGeneratePackageGolden -update and review the diff. Got:\n%s", golden, code)
+			}
+		})
+	}
+}
+
+func TestGeneratePackageFormatError(t *testing.T) {
+	_, err := GeneratePackage(nil, "1api", Options{})
+	if err == nil {
+		t.Fatal("GeneratePackage() expected an error for an invalid package name")
+	}
+	for _, expected := range []string{"generated code doesn't format: 3:9: ", "   3\tpackage 1api\n"} {
+		if !strings.Contains(err.Error(), expected) {
+			t.Errorf("GeneratePackage() error = %q, want it to contain %q", err, expected)
+		}
+	}
+}
+
+func TestGeneratePackageHeader(t *testing.T) {
+	structs := []parse.StructInfo{{
+		Name: "Params",
+		Tags: []parse.TagInfo{{FieldName: "Page", FieldType: "int", Bind: &parse.BindTag{Type: "query"}}},
+	}}
+
+	code, err := GeneratePackage(structs, "api", Options{})
+	if err != nil {
+		t.Fatalf("GeneratePackage() error = %v", err)
+	}
+	if !strings.HasPrefix(code, "// Code generated by go-wrangler. DO NOT EDIT.\n\npackage api\n") {
+		t.Errorf("GeneratePackage() doesn't start with the generated file header:\n%s", code)
+	}
+	if n := strings.Count(code, "DO NOT EDIT"); n != 1 {
+		t.Errorf("GeneratePackage() has %d headers, want 1:\n%s", n, code)
+	}
+	file, err := parser.ParseFile(token.NewFileSet(), "", code, parser.ParseComments|parser.PackageClauseOnly)
+	if err != nil {
+		t.Fatalf("Failed to parse generated code: %v", err)
+	}
+	if !ast.IsGenerated(file) {
+		t.Errorf("ast.IsGenerated() = false for:\n%s", code)
+	}
+
+	// The source hash changes with the structs
+	hashed, err := GeneratePackage(structs, "api", Options{SourceHash: true})
+	if err != nil {
+		t.Fatalf("GeneratePackage() error = %v", err)
+	}
+	structs[0].Tags[0].Bind.Required = true
+	changed, err := GeneratePackage(structs, "api", Options{SourceHash: true})
+	if err != nil {
+		t.Fatalf("GeneratePackage() error = %v", err)
+	}
+	hash := func(code string) string {
+		_, rest, _ := strings.Cut(code, "// Source hash: ")
+		line, _, _ := strings.Cut(rest, "\n")
+		return line
+	}
+	if !strings.HasPrefix(hash(hashed), "sha256:") || hash(hashed) == hash(changed) {
+		t.Errorf("Source hashes = %q and %q, want different sha256 hashes", hash(hashed), hash(changed))
+	}
+}
@@ -0,0 +1,23 @@
+package api
+
+import (
+	"net/http"
+	"net/netip"
+	"time"
+)
+
+type UserID int64
+
+type ListOrders struct {
+	User     UserID        `bind:"path=user,required"`
+	Page     int           `bind:"query=page,default=1" validate:"min=1"`
+	Status   []string      `bind:"query=status,explode=false"`
+	IDs      []UserID      `bind:"query=id"`
+	Since    *time.Time    `bind:"query=since,layout=date"`
+	Timeout  time.Duration `bind:"header=X-Timeout,default=30s"`
+	Client   netip.Addr    `bind:"header=X-Client-IP"`
+	Session  *http.Cookie  `bind:"cookie=session"`
+	Theme    string        `bind:"cookie=theme,default=light"`
+	Verbose  bool          `bind:"query=verbose"`
+	MaxPrice *float64      `bind:"query=max_price" validate:"gt=0"`
+}
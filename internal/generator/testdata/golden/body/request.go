@@ -0,0 +1,18 @@
+package api
+
+type Item struct {
+	SKU      string `json:"sku"`
+	Quantity int    `json:"quantity"`
+}
+
+type CreateOrder struct {
+	Store string `bind:"path=store,required"`
+	Email string `json:"email" bind:"body,maxbytes=1048576" validate:"format=email"`
+	Items []Item `json:"items" bind:"body" validate:"min=1"`
+	Note  string `json:"note" bind:"body"`
+}
+
+type ReplaceOrder struct {
+	ID    string       `bind:"path=id"`
+	Order *CreateOrder `bind:"body,whole,allowunknown"`
+}
@@ -0,0 +1,10 @@
+package api
+
+import "mime/multipart"
+
+type Upload struct {
+	Title  string                  `bind:"form=title,required" validate:"maxlen=100"`
+	Tags   []string                `bind:"form=tag"`
+	Avatar *multipart.FileHeader   `bind:"file=avatar,maxbytes=2097152,accept=image/png|image/jpeg"`
+	Files  []*multipart.FileHeader `bind:"file=files,accept=application/*"`
+}
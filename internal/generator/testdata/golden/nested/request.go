@@ -0,0 +1,50 @@
+package api
+
+import (
+	"context"
+	"errors"
+)
+
+type Pagination struct {
+	Page  int `bind:"query=page,default=1" validate:"min=1"`
+	Limit int `bind:"query=limit,default=20" validate:"min=1,max=100"`
+}
+
+type Filter struct {
+	Status string `bind:"query=status" validate:"oneof=open|closed"`
+	Owner  string `bind:"query=owner"`
+}
+
+func (p Pagination) Validate() error {
+	if p.Page > 1 && p.Limit > 50 {
+		return errors.New("limit is at most 50 past the first page")
+	}
+	return nil
+}
+
+func (f *Filter) ValidateContext(ctx context.Context) error {
+	return nil
+}
+
+type ListTickets struct {
+	Pagination
+	Filter Filter `prefix:"filter[...]"`
+}
+
+type Line struct {
+	SKU      string `json:"sku" validate:"minlen=1"`
+	Quantity int    `json:"quantity" validate:"min=1"`
+}
+
+type Address struct {
+	City string `json:"city" validate:"minlen=1"`
+}
+
+type Order struct {
+	Lines    []Line             `json:"lines" bind:"body"`
+	Shipping *Address           `json:"shipping" bind:"body"`
+	Billing  Address            `json:"billing" bind:"body"`
+	Extras   map[string]Line    `json:"extras" bind:"body"`
+	Gifts    []*Line            `json:"gifts" bind:"body"`
+	Notes    map[string]Address `json:"notes"`
+}
@@ -0,0 +1,46 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+)
+
+//wrangler:validator sku validSKU
+
+func validSKU(s string) error {
+	if !strings.HasPrefix(s, "SKU-") {
+		return errors.New("must start with SKU-")
+	}
+	return nil
+}
+
+type Signup struct {
+	Username string  `validate:"minlen=3,maxlen=20,pattern=^[a-z0-9_]+$"`
+	Email    string  `validate:"format=email"`
+	Password string  `validate:"minlen=8"`
+	Confirm  string  `validate:"eqfield=Password"`
+	Age      int     `validate:"min=18,max=130"`
+	Plan     string  `validate:"oneof=free|pro|team"`
+	Seats    *int    `validate:"min=1,required_if=Plan:team"`
+	Referrer *string `validate:"excluded_with=Coupon"`
+	Coupon   *string `validate:"len=8"`
+	Product  string  `validate:"sku"`
+}
+
+type Booking struct {
+	Start  time.Time     `validate:"gt=2024-01-01"`
+	End    time.Time     `validate:"gtfield=Start"`
+	Length time.Duration `validate:"min=15m,max=8h"`
+	Phone  string        `validate:"required_without=Email"`
+	Email  string        `validate:"required_without=Phone"`
+}
+
+func (b *Booking) Validate() error {
+	return nil
+}
+
+func (b *Booking) ValidateContext(ctx context.Context) error {
+	return nil
+}
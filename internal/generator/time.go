@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// timeLayout is a built-in layout of the layout bind option. Times are either parsed with the
+// layout of the time package named by constant, or as an integer Unix time converted by the
+// function unix, e.g. time.UnixMilli, in unit.
+type timeLayout struct {
+	constant string
+	layout   string
+	unix     string
+	unit     string
+}
+
+var timeLayouts = map[string]timeLayout{
+	"rfc3339":     {constant: "time.RFC3339", layout: time.RFC3339},
+	"rfc3339nano": {constant: "time.RFC3339Nano", layout: time.RFC3339Nano},
+	"rfc1123":     {constant: "time.RFC1123", layout: time.RFC1123},
+	"date":        {constant: "time.DateOnly", layout: time.DateOnly},
+	"datetime":    {constant: "time.DateTime", layout: time.DateTime},
+	"unix":        {unix: "time.Unix(val, 0)", unit: "seconds"},
+	"unixmilli":   {unix: "time.UnixMilli(val)", unit: "milliseconds"},
+	"unixmicro":   {unix: "time.UnixMicro(val)", unit: "microseconds"},
+}
+
+// writeTimeConversion writes code parsing the string expression raw as a time.Time in the layout
+// of a bind tag, RFC 3339 by default, and storing it with assign. Values that don't parse fail
+// with an error showing the expected format. Other layouts than the built-in ones are Go layouts,
+// which must hold at least one element of the reference time.
+func (w *codeWriter) writeTimeConversion(depth int, layout, raw string, ref fieldRef, assign assignFunc) error {
+	if layout == "" {
+		layout = "rfc3339"
+	}
+	w.addImports("time")
+	named, ok := timeLayouts[layout]
+	if !ok {
+		// A layout without elements formats any time as itself
+		if t := time.Date(2017, time.November, 23, 18, 49, 37, 0, time.UTC); t.Format(layout) == layout {
+			return fmt.Errorf("invalid layout %q: not a built-in layout, nor a Go layout", layout)
+		}
+		named = timeLayout{constant: strconv.Quote(layout), layout: layout}
+	}
+
+	if named.unix != "" {
+		w.addImports("strconv")
+		w.linef(depth, "if val, err := strconv.ParseInt(%s, 10, 64); err != nil {", raw)
+		w.writeFail(depth+1, ref, "type", fmt.Sprintf("%s must be a Unix time in %s", ref.param, named.unit))
+		w.linef(depth, "} else {")
+		assign(depth+1, named.unix)
+		w.linef(depth, "}")
+		return nil
+	}
+	w.linef(depth, "if val, err := time.Parse(%s, %s); err != nil {", named.constant, raw)
+	w.writeFailExpr(depth+1, ref, "type", strconv.Quote(fmt.Sprintf("%s must be a time formatted as %s", ref.param, named.layout)), "err")
+	w.linef(depth, "} else {")
+	assign(depth+1, "val")
+	w.linef(depth, "}")
+	return nil
+}
+
+// writeDurationConversion writes code parsing the string expression raw as a time.Duration and
+// storing it with assign.
+func (w *codeWriter) writeDurationConversion(depth int, raw string, ref fieldRef, assign assignFunc) {
+	w.addImports("time")
+	w.linef(depth, "if val, err := time.ParseDuration(%s); err != nil {", raw)
+	w.writeFailExpr(depth+1, ref, "type", strconv.Quote(ref.param+" must be a duration such as 1h30m or 500ms"), "err")
+	w.linef(depth, "} else {")
+	assign(depth+1, "val")
+	w.linef(depth, "}")
+}
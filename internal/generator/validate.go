@@ -0,0 +1,682 @@
+package generator
+
+import (
+	"cmp"
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pangobit/go-wrangler/internal/parse"
+)
+
+// GenerateValidateFunction generates Go code for a validate function that validates the struct fields according to the validate tags.
+// Like the bind function, it reports failures as wrangler.Errors. Rules that don't apply to the type of
+// their field, and patterns that don't compile, are reported as errors.
+// Nested structs are then validated by their own generated function, and the Validate and ValidateContext
+// methods of flattened structs called, before the struct's own. Structs with a ValidateContext method, or nesting a struct that
+// takes a context, also get a Validate<Struct>Context function taking the context to pass it.
+func GenerateValidateFunction(structInfo parse.StructInfo, opts Options) (string, []string, error) {
+	w := &codeWriter{failFast: opts.FailFast}
+
+	// Patterns are compiled once, when the package is initialised
+	hasPatterns := false
+	for _, tag := range structInfo.Tags {
+		if tag.Validate == nil || tag.Validate.Pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(tag.Validate.Pattern); err != nil {
+			return "", nil, fmt.Errorf("%s.%s: invalid pattern: %w", structInfo.Name, tag.FieldName, err)
+		}
+		w.addImports("regexp")
+		w.linef(0, "var %s = regexp.MustCompile(%q)", patternVar(structInfo.Name, tag.FieldName), tag.Validate.Pattern)
+		hasPatterns = true
+	}
+	if hasPatterns {
+		w.WriteString("\n")
+	}
+
+	// Function signature
+	if structInfo.HasValidateContext || structInfo.Context {
+		w.addImports("context")
+		w.linef(0, "func Validate%s(s *%s) error {", structInfo.Name, structInfo.Name)
+		w.linef(1, "return Validate%sContext(context.Background(), s)", structInfo.Name)
+		w.linef(0, "}")
+		w.WriteString("\n")
+		w.linef(0, "func Validate%sContext(ctx context.Context, s *%s) error {", structInfo.Name, structInfo.Name)
+	} else {
+		w.linef(0, "func Validate%s(s *%s) error {", structInfo.Name, structInfo.Name)
+	}
+	w.writeErrsDecl()
+
+	// Validation logic
+	for _, tag := range structInfo.Tags {
+		if tag.Validate == nil {
+			continue
+		}
+		if err := w.writeFieldValidation(structInfo, tag); err != nil {
+			return "", nil, fmt.Errorf("%s.%s: %w", structInfo.Name, tag.FieldName, err)
+		}
+	}
+
+	// Nested structs
+	for _, nested := range structInfo.Nested {
+		w.writeNested(nested)
+	}
+
+	// Struct-level hooks, those of flattened structs first
+	for _, f := range structInfo.Flattened {
+		if f.HasValidate {
+			w.writeHook(f.Path, "s."+f.FieldName+".Validate()")
+		}
+		if f.HasValidateContext {
+			w.writeHook(f.Path, "s."+f.FieldName+".ValidateContext(ctx)")
+		}
+	}
+	if structInfo.HasValidate {
+		w.writeHook("", "s.Validate()")
+	}
+	if structInfo.HasValidateContext {
+		w.writeHook("", "s.ValidateContext(ctx)")
+	}
+
+	w.writeReturn()
+	w.linef(0, "}")
+
+	return w.String(), w.imports, nil
+}
+
+// writeHook writes a call to a validation method of the struct or of a struct flattened into it,
+// merging the error it returns, under path for the structs flattened into a field.
+func (w *codeWriter) writeHook(path, call string) {
+	w.addImports(wranglerImport)
+	merge := func(err string) string { return fmt.Sprintf("Append(%s)", err) }
+	if path != "" {
+		merge = func(err string) string { return fmt.Sprintf("Nest(%q, %s)", path, err) }
+	}
+	if w.failFast {
+		w.linef(1, "if err := %s; err != nil {", call)
+		w.linef(2, "return wrangler.Errors{}.%s.Err()", merge("err"))
+		w.linef(1, "}")
+		return
+	}
+	w.linef(1, "errs = errs.%s", merge(call))
+}
+
+// writeNested writes the validation of a field holding structs with their own generated validate
+// function, reporting their failures under the path of the struct, e.g. Items[3].Quantity. Nil
+// pointers are skipped, and map keys are sorted when they're ordered so failures keep their order.
+func (w *codeWriter) writeNested(n parse.NestedField) {
+	field := "s." + n.FieldName
+	switch n.Kind {
+	case parse.NestedStruct:
+		if !n.Pointer {
+			w.writeNest(1, strconv.Quote(n.FieldName), n, "&"+field)
+			return
+		}
+		w.linef(1, "if %s != nil {", field)
+		w.writeNest(2, strconv.Quote(n.FieldName), n, field)
+		w.linef(1, "}")
+	case parse.NestedSlice:
+		w.addImports("fmt")
+		path := fmt.Sprintf("fmt.Sprintf(%q, i)", n.FieldName+"[%d]")
+		if !n.Pointer {
+			w.linef(1, "for i := range %s {", field)
+			w.writeNest(2, path, n, "&"+field+"[i]")
+			w.linef(1, "}")
+			return
+		}
+		w.linef(1, "for i, v := range %s {", field)
+		w.linef(2, "if v != nil {")
+		w.writeNest(3, path, n, "v")
+		w.linef(2, "}")
+		w.linef(1, "}")
+	case parse.NestedMap:
+		w.addImports("fmt")
+		path := fmt.Sprintf("fmt.Sprintf(%q, k)", n.FieldName+"[%v]")
+		// Keys whose type the parser didn't resolve are taken as written
+		if key := cmp.Or(n.KeyUnderlyingType, n.KeyType); key == "string" || isNumericType(key) {
+			w.addImports("maps", "slices")
+			w.linef(1, "for _, k := range slices.Sorted(maps.Keys(%s)) {", field)
+			w.linef(2, "v := %s[k]", field)
+		} else {
+			w.linef(1, "for k, v := range %s {", field)
+		}
+		if !n.Pointer {
+			w.writeNest(2, path, n, "&v")
+			w.linef(1, "}")
+			return
+		}
+		w.linef(2, "if v != nil {")
+		w.writeNest(3, path, n, "v")
+		w.linef(2, "}")
+		w.linef(1, "}")
+	}
+}
+
+// writeNest writes a call to the validate function of a nested struct, arg pointing to it, and
+// merges the failures under path, a Go expression.
+func (w *codeWriter) writeNest(depth int, path string, n parse.NestedField, arg string) {
+	w.addImports(wranglerImport)
+	call := fmt.Sprintf("Validate%s(%s)", n.Struct, arg)
+	if n.Context {
+		call = fmt.Sprintf("Validate%sContext(ctx, %s)", n.Struct, arg)
+	}
+	if w.failFast {
+		w.linef(depth, "if err := %s; err != nil {", call)
+		w.linef(depth+1, "return wrangler.Errors{}.Nest(%s, err).Err()", path)
+		w.linef(depth, "}")
+		return
+	}
+	w.linef(depth, "errs = errs.Nest(%s, %s)", path, call)
+}
+
+// stringFormat is a format of the format rule, checked by a function of the wrangler runtime package.
+type stringFormat struct {
+	check string
+	name  string
+}
+
+var stringFormats = map[string]stringFormat{
+	"email":     {"IsEmail", "email address"},
+	"uri":       {"IsURI", "URI"},
+	"url":       {"IsURL", "URL"},
+	"uuid":      {"IsUUID", "UUID"},
+	"ipv4":      {"IsIPv4", "IPv4 address"},
+	"ipv6":      {"IsIPv6", "IPv6 address"},
+	"cidr":      {"IsCIDR", "CIDR prefix"},
+	"hostname":  {"IsHostname", "hostname"},
+	"date":      {"IsDate", "date (YYYY-MM-DD)"},
+	"date-time": {"IsDateTime", "RFC 3339 date-time"},
+}
+
+// patternVar returns the name of the package level variable holding the compiled pattern of a field,
+// e.g. wrangler_Signup_Username_pattern. The struct name and the selectors of the field are joined
+// by underscores, and their own underscores written _0, so that no two fields share a variable:
+// identifiers don't start with a digit, so an underscore followed by 0 never joins two names.
+func patternVar(structName, fieldName string) string {
+	names := []string{"wrangler"}
+	for _, name := range append([]string{structName}, strings.Split(fieldName, ".")...) {
+		names = append(names, strings.ReplaceAll(name, "_", "_0"))
+	}
+	return strings.Join(append(names, "pattern"), "_")
+}
+
+// validateRules returns the names of the rules set in a validate tag, in the order they are checked.
+func validateRules(v *parse.ValidateTag) []string {
+	var rules []string
+	for _, rule := range []struct {
+		name string
+		set  bool
+	}{
+		{"min", v.Min != nil},
+		{"max", v.Max != nil},
+		{"gt", v.Gt != nil},
+		{"lt", v.Lt != nil},
+		{"minlen", v.MinLen != nil},
+		{"maxlen", v.MaxLen != nil},
+		{"len", v.Len != nil},
+		{"pattern", v.Pattern != ""},
+		{"oneof", len(v.OneOf) > 0},
+		{"format", v.Format != ""},
+	} {
+		if rule.set {
+			rules = append(rules, rule.name)
+		}
+	}
+	return rules
+}
+
+// checkRules returns an error for the first rule of a validate tag that doesn't apply to fields of typ.
+// Bounds apply to numbers, durations, times and the number of items of slices, the length rules,
+// patterns and formats apply to strings, and oneof to strings and numbers.
+func checkRules(v *parse.ValidateTag, typ string) error {
+	var applicable []string
+	switch {
+	case isSliceType(typ):
+		applicable = []string{"min", "max", "gt", "lt"}
+	case isNumericType(baseType(typ)):
+		applicable = []string{"min", "max", "gt", "lt", "oneof"}
+	case baseType(typ) == "time.Duration" || baseType(typ) == "time.Time":
+		applicable = []string{"min", "max", "gt", "lt"}
+	case baseType(typ) == "string":
+		applicable = []string{"minlen", "maxlen", "len", "pattern", "oneof", "format"}
+	}
+	for _, rule := range validateRules(v) {
+		if slices.Contains(applicable, rule) {
+			continue
+		}
+		if baseType(typ) == "string" && (rule == "min" || rule == "max") {
+			return fmt.Errorf("%s doesn't apply to string fields, use %slen to bound their length", rule, rule)
+		}
+		return fmt.Errorf("%s doesn't apply to %s fields", rule, typ)
+	}
+	return nil
+}
+
+// writeFieldValidation writes the checks of the validate tag of a field. Rules apply to fields by
+// their underlying type, and pointer fields are only validated when set, apart from the rules
+// making them required or excluded. Custom validators must take the field's type, ignoring pointers.
+func (w *codeWriter) writeFieldValidation(structInfo parse.StructInfo, tag parse.TagInfo) error {
+	v := tag.Validate
+	if err := checkRules(v, underlyingType(tag)); err != nil {
+		return err
+	}
+	comparisons, err := fieldComparisons(structInfo, tag)
+	if err != nil {
+		return err
+	}
+
+	format, ok := stringFormats[v.Format]
+	if v.Format != "" && !ok {
+		return fmt.Errorf("unknown format %q", v.Format)
+	}
+
+	ref := fieldRef{field: tag.FieldName}
+	if tag.Bind != nil {
+		ref = bindRef(tag)
+	}
+	typ := baseType(underlyingType(tag))
+
+	var oneOf []string
+	for _, value := range v.OneOf {
+		lit := strconv.Quote(value)
+		if typ != "string" {
+			var err error
+			if lit, err = literal(typ, value); err != nil {
+				return fmt.Errorf("oneof value %w", err)
+			}
+		}
+		oneOf = append(oneOf, lit)
+	}
+
+	for _, validator := range v.Validators {
+		if validator.ParamType != baseType(tag.FieldType) {
+			return fmt.Errorf("validator %s takes %s values, not %s", validator.Name, validator.ParamType, baseType(tag.FieldType))
+		}
+	}
+
+	depth, field := 1, "s."+tag.FieldName
+	wrapped := strings.HasPrefix(tag.FieldType, "*") && (len(validateRules(v)) > 0 || len(comparisons) > 0 || len(v.Validators) > 0)
+	if wrapped {
+		w.linef(1, "if %s != nil {", field)
+		depth, field = 2, "*"+field
+	}
+
+	// Strings of named types are converted for the functions taking a string
+	str := field
+	if typ == "string" && valueType(tag) != "string" {
+		str = "string(" + field + ")"
+	}
+
+	// check writes a condition failing rule with message when it holds
+	check := func(rule, cond, message string) {
+		w.linef(depth, "if %s {", cond)
+		w.writeFail(depth+1, ref, rule, message)
+		w.linef(depth, "}")
+	}
+
+	if err := w.writeBounds(depth, ref, tag, v); err != nil {
+		return err
+	}
+
+	// String lengths are counted in runes
+	if v.MinLen != nil || v.MaxLen != nil || v.Len != nil {
+		w.addImports("unicode/utf8")
+	}
+	if v.MinLen != nil {
+		check("minlen", fmt.Sprintf("utf8.RuneCountInString(%s) < %d", str, *v.MinLen), fmt.Sprintf("%s must be at least %d characters long", tag.FieldName, *v.MinLen))
+	}
+	if v.MaxLen != nil {
+		check("maxlen", fmt.Sprintf("utf8.RuneCountInString(%s) > %d", str, *v.MaxLen), fmt.Sprintf("%s must be at most %d characters long", tag.FieldName, *v.MaxLen))
+	}
+	if v.Len != nil {
+		check("len", fmt.Sprintf("utf8.RuneCountInString(%s) != %d", str, *v.Len), fmt.Sprintf("%s must be exactly %d characters long", tag.FieldName, *v.Len))
+	}
+	if v.Pattern != "" {
+		check("pattern", fmt.Sprintf("!%s.MatchString(%s)", patternVar(structInfo.Name, tag.FieldName), str), fmt.Sprintf("%s must match the pattern %s", tag.FieldName, v.Pattern))
+	}
+	if len(oneOf) > 0 {
+		conds := make([]string, len(oneOf))
+		for i, lit := range oneOf {
+			conds[i] = field + " != " + lit
+		}
+		check("oneof", strings.Join(conds, " && "), fmt.Sprintf("%s must be one of %s", tag.FieldName, strings.Join(v.OneOf, ", ")))
+	}
+	if v.Format != "" {
+		check("format", fmt.Sprintf("!wrangler.%s(%s)", format.check, str), fmt.Sprintf("%s must be a valid %s", tag.FieldName, format.name))
+	}
+	for _, c := range comparisons {
+		check(c.rule, c.cond, c.message)
+	}
+	// Custom validators are called last, with the message of the error they return
+	for _, validator := range v.Validators {
+		if validator.ImportPath != "" {
+			w.addImports(validator.ImportPath)
+		}
+		w.linef(depth, "if err := %s(%s); err != nil {", validator.Func, field)
+		w.writeFailExpr(depth+1, ref, validator.Name, strconv.Quote(tag.FieldName+": ")+" + err.Error()", "err")
+		w.linef(depth, "}")
+	}
+
+	if wrapped {
+		w.linef(1, "}")
+	}
+
+	return w.writePresenceRules(structInfo, tag, ref)
+}
+
+// comparison is a check comparing a field with a sibling field, failing rule with message when cond holds.
+type comparison struct {
+	rule    string
+	cond    string
+	message string
+}
+
+// fieldComparisons returns the checks of the eqfield, nefield, gtfield, gtefield, ltfield and ltefield
+// rules of a field, for use where the field is known to be set. Both fields must have the same type,
+// ignoring pointers: equality applies to strings, numbers, booleans, durations and times, and order to
+// numbers, durations and times. Sibling pointer fields are only compared when set.
+func fieldComparisons(structInfo parse.StructInfo, tag parse.TagInfo) ([]comparison, error) {
+	v := tag.Validate
+	typ := baseType(underlyingType(tag))
+
+	var comparisons []comparison
+	for _, c := range []struct {
+		rule     string
+		other    string
+		ordered  bool
+		op       string
+		timeCond string
+		message  string
+	}{
+		{"eqfield", v.EqField, false, "!=", "!%s.Equal(%s)", "equal %s"},
+		{"nefield", v.NeField, false, "==", "%s.Equal(%s)", "not equal %s"},
+		{"gtfield", v.GtField, true, "<=", "!%s.After(%s)", "be greater than %s"},
+		{"gtefield", v.GteField, true, "<", "%s.Before(%s)", "be greater than or equal to %s"},
+		{"ltfield", v.LtField, true, ">=", "!%s.Before(%s)", "be less than %s"},
+		{"ltefield", v.LteField, true, ">", "%s.After(%s)", "be less than or equal to %s"},
+	} {
+		if c.other == "" {
+			continue
+		}
+		otherType, err := siblingType(structInfo, tag, c.other)
+		if err != nil {
+			return nil, fmt.Errorf("%s %w", c.rule, err)
+		}
+		if baseType(otherType) != baseType(tag.FieldType) {
+			return nil, fmt.Errorf("%s can't compare %s with %s field %s", c.rule, tag.FieldType, otherType, c.other)
+		}
+		comparable := isNumericType(typ) || typ == "time.Duration" || typ == "time.Time"
+		if !c.ordered {
+			comparable = comparable || typ == "string" || typ == "bool"
+		}
+		if !comparable {
+			return nil, fmt.Errorf("%s doesn't apply to %s fields", c.rule, tag.FieldType)
+		}
+
+		field, other := "s."+tag.FieldName, "s."+c.other
+		if strings.HasPrefix(tag.FieldType, "*") {
+			field = "*" + field
+		}
+		if strings.HasPrefix(otherType, "*") {
+			other = "*" + other
+		}
+		message := c.message
+		var cond string
+		if typ == "time.Time" {
+			// Methods of time.Time can be called through a pointer, so the field isn't dereferenced
+			cond = fmt.Sprintf(c.timeCond, "s."+tag.FieldName, other)
+			switch c.rule {
+			case "gtfield":
+				message = "be after %s"
+			case "gtefield":
+				message = "not be before %s"
+			case "ltfield":
+				message = "be before %s"
+			case "ltefield":
+				message = "not be after %s"
+			}
+		} else {
+			cond = fmt.Sprintf("%s %s %s", field, c.op, other)
+		}
+		if strings.HasPrefix(otherType, "*") {
+			cond = fmt.Sprintf("s.%s != nil && %s", c.other, cond)
+		}
+		comparisons = append(comparisons, comparison{c.rule, cond, tag.FieldName + " must " + fmt.Sprintf(message, c.other)})
+	}
+	return comparisons, nil
+}
+
+// writePresenceRules writes the checks of the required_with, required_without, required_if and
+// excluded_with rules of a field, which depend on whether the field and its siblings are set.
+func (w *codeWriter) writePresenceRules(structInfo parse.StructInfo, tag parse.TagInfo, ref fieldRef) error {
+	v := tag.Validate
+	if len(v.RequiredWith) == 0 && len(v.RequiredWithout) == 0 && len(v.RequiredIf) == 0 && len(v.ExcludedWith) == 0 {
+		return nil
+	}
+	set, unset, err := presence(tag.FieldName, underlyingType(tag))
+	if err != nil {
+		return err
+	}
+
+	// siblings returns the conditions of a list of sibling fields being set, or not set
+	siblings := func(rule string, fields []string, isSet bool) ([]string, error) {
+		conds := make([]string, len(fields))
+		for i, other := range fields {
+			if _, err := siblingType(structInfo, tag, other); err != nil {
+				return nil, fmt.Errorf("%s %w", rule, err)
+			}
+			otherSet, otherUnset, err := presence(other, siblingUnderlyingType(structInfo, other))
+			if err != nil {
+				return nil, fmt.Errorf("%s %w", rule, err)
+			}
+			conds[i] = otherSet
+			if !isSet {
+				conds[i] = otherUnset
+			}
+		}
+		return conds, nil
+	}
+	check := func(rule string, conds []string, message string) {
+		w.linef(1, "if %s {", strings.Join(conds, " && "))
+		w.writeFail(2, ref, rule, message)
+		w.linef(1, "}")
+	}
+
+	if len(v.RequiredWith) > 0 {
+		conds, err := siblings("required_with", v.RequiredWith, true)
+		if err != nil {
+			return err
+		}
+		check("required_with", []string{unset, anyOf(conds)}, fmt.Sprintf("%s is required when %s is set", tag.FieldName, strings.Join(v.RequiredWith, " or ")))
+	}
+	if len(v.RequiredWithout) > 0 {
+		conds, err := siblings("required_without", v.RequiredWithout, false)
+		if err != nil {
+			return err
+		}
+		check("required_without", []string{unset, anyOf(conds)}, fmt.Sprintf("%s is required when %s is not set", tag.FieldName, strings.Join(v.RequiredWithout, " or ")))
+	}
+	if len(v.RequiredIf) > 0 {
+		conds := []string{unset}
+		descs := make([]string, len(v.RequiredIf))
+		for i, c := range v.RequiredIf {
+			otherType, err := siblingType(structInfo, tag, c.Field)
+			if err != nil {
+				return fmt.Errorf("required_if %w", err)
+			}
+			lit, err := literal(baseType(siblingUnderlyingType(structInfo, c.Field)), c.Value)
+			if err != nil {
+				return fmt.Errorf("required_if value of %s %w", c.Field, err)
+			}
+			if strings.HasPrefix(otherType, "*") {
+				conds = append(conds, fmt.Sprintf("s.%s != nil && *s.%s == %s", c.Field, c.Field, lit))
+			} else {
+				conds = append(conds, fmt.Sprintf("s.%s == %s", c.Field, lit))
+			}
+			descs[i] = c.Field + " is " + c.Value
+		}
+		check("required_if", conds, fmt.Sprintf("%s is required when %s", tag.FieldName, strings.Join(descs, " and ")))
+	}
+	// Each excluded field is checked on its own, to tell which one conflicts
+	for _, other := range v.ExcludedWith {
+		conds, err := siblings("excluded_with", []string{other}, true)
+		if err != nil {
+			return err
+		}
+		check("excluded_with", []string{set, conds[0]}, fmt.Sprintf("%s must not be set when %s is set", tag.FieldName, other))
+	}
+	return nil
+}
+
+// siblingType returns the type of a sibling field named by a rule of tag.
+func siblingType(structInfo parse.StructInfo, tag parse.TagInfo, name string) (string, error) {
+	if name == tag.FieldName {
+		return "", fmt.Errorf("refers to the field itself")
+	}
+	typ, ok := structInfo.FieldTypes[name]
+	if !ok {
+		return "", fmt.Errorf("refers to unknown field %s", name)
+	}
+	return typ, nil
+}
+
+// siblingUnderlyingType returns the underlying type of a sibling field, or its type as written for
+// structs the parser didn't resolve.
+func siblingUnderlyingType(structInfo parse.StructInfo, name string) string {
+	if typ, ok := structInfo.UnderlyingTypes[name]; ok {
+		return typ
+	}
+	return structInfo.FieldTypes[name]
+}
+
+// presence returns the conditions of a field of typ being set and not set, i.e. differing from its
+// zero value, or an empty slice or map.
+func presence(name, typ string) (set, unset string, err error) {
+	field := "s." + name
+	switch {
+	case strings.HasPrefix(typ, "*"):
+		return field + " != nil", field + " == nil", nil
+	case strings.HasPrefix(typ, "[]"), strings.HasPrefix(typ, "map["):
+		return "len(" + field + ") > 0", "len(" + field + ") == 0", nil
+	case typ == "string":
+		return field + ` != ""`, field + ` == ""`, nil
+	case typ == "bool":
+		return field, "!" + field, nil
+	case isNumericType(typ), typ == "time.Duration":
+		return field + " != 0", field + " == 0", nil
+	case typ == "time.Time":
+		return "!" + field + ".IsZero()", field + ".IsZero()", nil
+	}
+	return "", "", fmt.Errorf("can't tell whether %s field %s is set", typ, name)
+}
+
+// anyOf joins conditions of which any must hold.
+func anyOf(conds []string) string {
+	if len(conds) == 1 {
+		return conds[0]
+	}
+	return "(" + strings.Join(conds, " || ") + ")"
+}
+
+// bound is a bound rule of a validate tag, with how it is checked for each kind of field.
+// op is the comparison operator failing the bound for numbers, durations and slice lengths, and
+// timeCond the condition failing it for times, given the field and the bound. message, timeMessage
+// and itemsMessage describe the bound for numbers and durations, for times and for slices.
+type bound struct {
+	rule         string
+	value        *parse.Bound
+	op           string
+	timeCond     string
+	message      string
+	timeMessage  string
+	itemsMessage string
+}
+
+// writeBounds writes the checks of the min, max, gt and lt bounds of a field. Numbers are compared
+// with the bound converted to the field type, slices by their number of items, durations with the
+// bound as a time.Duration and times with Before and After.
+func (w *codeWriter) writeBounds(depth int, ref fieldRef, tag parse.TagInfo, v *parse.ValidateTag) error {
+	field := "s." + tag.FieldName
+	if strings.HasPrefix(tag.FieldType, "*") {
+		field = "*" + field
+	}
+	typ := baseType(underlyingType(tag))
+
+	for _, b := range []bound{
+		{"min", v.Min, "<", "%s.Before(%s)", "at least %s", "not be before %s", "at least %s items"},
+		{"max", v.Max, ">", "%s.After(%s)", "at most %s", "not be after %s", "at most %s items"},
+		{"gt", v.Gt, "<=", "!%s.After(%s)", "greater than %s", "be after %s", "more than %s items"},
+		{"lt", v.Lt, ">=", "!%s.Before(%s)", "less than %s", "be before %s", "fewer than %s items"},
+	} {
+		if b.value == nil {
+			continue
+		}
+		var cond, message string
+		switch {
+		case isSliceType(underlyingType(tag)):
+			lit, err := literal("int", b.value.Raw)
+			if err != nil {
+				return fmt.Errorf("%s %w", b.rule, err)
+			}
+			cond = fmt.Sprintf("len(%s) %s %s", field, b.op, lit)
+			message = "contain " + fmt.Sprintf(b.itemsMessage, b.value.Raw)
+		case typ == "time.Duration":
+			if b.value.Kind != parse.DurationBound && (b.value.Kind != parse.IntBound || b.value.Int != 0) {
+				return fmt.Errorf("%s %q must be a duration, e.g. 1m30s", b.rule, b.value.Raw)
+			}
+			// Zero and sub-microsecond bounds are plain numbers, which don't need the time package
+			lit := durationLiteral(b.value.Duration)
+			if strings.Contains(lit, "time.") {
+				w.addImports("time")
+			}
+			cond = fmt.Sprintf("%s %s %s", field, b.op, lit)
+			message = "be " + fmt.Sprintf(b.message, b.value.Raw)
+		case typ == "time.Time":
+			if b.value.Kind != parse.TimeBound {
+				return fmt.Errorf("%s %q must be an RFC 3339 time or date", b.rule, b.value.Raw)
+			}
+			w.addImports("time")
+			// Methods of time.Time can be called through a pointer, so the field isn't dereferenced
+			t := b.value.Time
+			cond = fmt.Sprintf(b.timeCond, "s."+tag.FieldName, fmt.Sprintf("time.Unix(%d, %d)", t.Unix(), t.Nanosecond()))
+			message = fmt.Sprintf(b.timeMessage, b.value.Raw)
+		default:
+			lit, err := literal(typ, b.value.Raw)
+			if err != nil {
+				return fmt.Errorf("%s %w", b.rule, err)
+			}
+			cond = fmt.Sprintf("%s %s %s", field, b.op, lit)
+			message = "be " + fmt.Sprintf(b.message, b.value.Raw)
+		}
+		w.linef(depth, "if %s {", cond)
+		w.writeFail(depth+1, ref, b.rule, tag.FieldName+" must "+message)
+		w.linef(depth, "}")
+	}
+	return nil
+}
+
+// durationLiteral returns d as a Go expression in the largest unit that divides it exactly.
+func durationLiteral(d time.Duration) string {
+	if d == 0 {
+		return "0"
+	}
+	for _, unit := range []struct {
+		size time.Duration
+		name string
+	}{
+		{time.Hour, "time.Hour"},
+		{time.Minute, "time.Minute"},
+		{time.Second, "time.Second"},
+		{time.Millisecond, "time.Millisecond"},
+		{time.Microsecond, "time.Microsecond"},
+	} {
+		if d%unit.size == 0 {
+			return fmt.Sprintf("%d * %s", d/unit.size, unit.name)
+		}
+	}
+	return fmt.Sprintf("%d", int64(d))
+}
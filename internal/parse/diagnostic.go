@@ -0,0 +1,99 @@
+package parse
+
+import (
+	"cmp"
+	"fmt"
+	"go/ast"
+	"go/token"
+	"slices"
+	"strings"
+)
+
+// Diagnostic is a malformed bind or validate tag found while parsing a package, or a field that
+// can't be bound as declared. The field is left out of the parsed structs.
+type Diagnostic struct {
+	Pos     token.Position
+	Message string
+}
+
+// String formats the diagnostic as file:line:col: message
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s: %s", d.Pos, d.Message)
+}
+
+// tagError is the error of parsing the tag with key of a struct field, at the position of the key,
+// or of a field that can't be bound, at the position of its type
+type tagError struct {
+	pos token.Pos
+	err error
+}
+
+// newTagError returns the tagError of the tag with key of field, or of the whole tag if key is empty
+func newTagError(field *ast.Field, key string, err error) tagError {
+	pos := field.Tag.Pos()
+	if i := strings.Index(field.Tag.Value, key+":"); key != "" && i >= 0 {
+		pos += token.Pos(i)
+	}
+	return tagError{pos, err}
+}
+
+// diagnostics returns the diagnostics of tag errors, sorted by position. The errors of the tags of
+// structs flattened into several others are only reported once.
+func diagnostics(fset *token.FileSet, errs []tagError) []Diagnostic {
+	var diags []Diagnostic
+	seen := make(map[token.Pos]bool)
+	for _, e := range errs {
+		if seen[e.pos] {
+			continue
+		}
+		seen[e.pos] = true
+		diags = append(diags, Diagnostic{Pos: fset.Position(e.pos), Message: e.err.Error()})
+	}
+	slices.SortFunc(diags, func(a, b Diagnostic) int {
+		return cmp.Or(strings.Compare(a.Pos.Filename, b.Pos.Filename), cmp.Compare(a.Pos.Offset, b.Pos.Offset))
+	})
+	return diags
+}
+
+// suggest returns a did-you-mean hint naming the candidate closest to word, or an empty string if
+// none is close enough to be a likely typo
+func suggest(word string, candidates []string) string {
+	best, bestDist := "", 0
+	for _, c := range candidates {
+		if d := editDistance(word, c); best == "" || d < bestDist {
+			best, bestDist = c, d
+		}
+	}
+	// Allow one edit for short words, and a third of the letters for longer ones
+	if best == "" || bestDist == 0 || bestDist > max(1, len(word)/3) {
+		return ""
+	}
+	return fmt.Sprintf(", did you mean %q?", best)
+}
+
+// editDistance returns the number of insertions, deletions, substitutions and transpositions of
+// adjacent bytes turning a into b
+func editDistance(a, b string) int {
+	// prev2, prev and cur are the rows of the distances from the prefixes of a to those of b
+	prev2 := make([]int, len(b)+1)
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] {
+				cur[j] = min(cur[j], prev2[j-2]+1)
+			}
+		}
+		prev2, prev, cur = prev, cur, prev2
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,120 @@
+package parse
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParsePackageDiagnostics(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"request.go": `package api
+
+type Page struct {
+	Size int ` + "`bind:\"query=size,defualt=20\"`" + `
+}
+
+type Request struct {
+	Page
+	Name  string ` + "`bind:\"qurey\" validate:\"mn=3\"`" + `
+	Email string ` + "`bind:\"query\" validate:\"format=email\"`" + `
+}
+`,
+	})
+
+	structs, _, diags, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+
+	file := filepath.Join(dir, "request.go")
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	// The tag of Page is flattened into Request, but only reported once
+	expected := []string{
+		file + `:4:12: invalid option "defualt=20" for bind type query, did you mean "default"?`,
+		file + `:9:16: invalid bind type "qurey", did you mean "query"?`,
+		file + `:9:29: unsupported validation rule "mn=3", did you mean "min"?`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParsePackage() diagnostics = %q, want %q", got, expected)
+	}
+
+	// Fields with malformed tags are left out
+	for _, s := range structs {
+		if s.Name != "Request" {
+			continue
+		}
+		if len(s.Tags) != 1 || s.Tags[0].FieldName != "Email" {
+			t.Errorf("Request tags = %+v, want Email only", s.Tags)
+		}
+	}
+}
+
+func TestParsePackagePointerDiagnostics(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"request.go": `package api
+
+type Pagination struct {
+	Page int ` + "`bind:\"query=page\"`" + `
+}
+
+type Limits struct {
+	Max int ` + "`validate:\"max=100\"`" + `
+}
+
+type Request struct {
+	*Pagination
+	Next   *Pagination
+	Body   *Pagination ` + "`bind:\"body\"`" + `
+	Limits *Limits
+	Name   string ` + "`bind:\"query\"`" + `
+}
+`,
+	})
+
+	_, _, diags, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+
+	// Pointers with their own bind tag, or to structs without bound fields, aren't reported
+	file := filepath.Join(dir, "request.go")
+	var got []string
+	for _, d := range diags {
+		got = append(got, d.String())
+	}
+	expected := []string{
+		file + `:12:2: embedded *Pagination isn't bound, as pointers to structs aren't flattened: embed Pagination instead`,
+		file + `:13:9: Next isn't bound, as pointers to structs aren't flattened: make it a Pagination`,
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("ParsePackage() diagnostics = %q, want %q", got, expected)
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	tests := []struct {
+		word     string
+		expected string
+	}{
+		{"qurey", `, did you mean "query"?`},
+		{"heder", `, did you mean "header"?`},
+		{"mn", `, did you mean "min"?`},
+		{"maxlne", `, did you mean "maxlen"?`},
+		{"query", ""},
+		{"xyz", ""},
+		{"required", ""},
+	}
+
+	candidates := []string{"header", "path", "query", "min", "max", "maxlen"}
+	for _, tt := range tests {
+		if got := suggest(tt.word, candidates); got != tt.expected {
+			t.Errorf("suggest(%q) = %q, want %q", tt.word, got, tt.expected)
+		}
+	}
+}
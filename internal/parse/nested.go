@@ -0,0 +1,387 @@
+package parse
+
+import (
+	"fmt"
+	"go/ast"
+	"go/types"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// nesting describes where the fields of a struct embedded or nested in another struct are bound
+// path is the Go selector of the struct relative to the outer struct, ending with a dot, empty for
+// the outer struct and the structs embedded in it. prefixes are the parameter name prefixes of the
+// enclosing nested structs, innermost first, and seen the struct types being flattened.
+type nesting struct {
+	path     string
+	prefixes []string
+	seen     []string
+}
+
+// structField is a field of a struct, named by its selector from the outer struct when it belongs
+// to a nested struct. flattened is set for fields holding a struct whose fields are flattened, and
+// embedded for embedded fields. keyType is the underlying type of the keys of map fields, once
+// resolved.
+type structField struct {
+	name      string
+	expr      ast.Expr
+	flattened bool
+	embedded  bool
+	keyType   string
+}
+
+// structFields returns the tagged fields of a struct type along with all its fields, in
+// declaration order. The fields of struct types of the package that are embedded, or nested in a
+// field without a bind tag, are flattened into it: embedded fields are promoted, keeping their name
+// unless the struct declares a field of the same name, and nested fields are named by their
+// selector, e.g. Filter.Status, with the prefix given by the nested field's prefix tag. Pointers to
+// structs aren't followed, and are reported when they hold bound fields, which would otherwise be
+// silently left unbound. Fields with malformed tags are left out, and their errors returned.
+func structFields(structType *ast.StructType, structs map[string]*ast.StructType, validators map[string]Validator, n nesting) ([]TagInfo, []structField, []tagError) {
+	var tags []TagInfo
+	var fields []structField
+	var errs []tagError
+
+	// Promoted fields are shadowed by the fields declared by the struct itself
+	declared := make(map[string]bool)
+	for _, field := range structType.Fields.List {
+		for _, name := range fieldNames(field) {
+			declared[name] = true
+		}
+	}
+
+	for _, field := range structType.Fields.List {
+		// Malformed tags are reported by processField
+		tag, _ := fieldTag(field)
+		typeName, nested := nestedStruct(field, tag, structs)
+		nested = nested && !slices.Contains(n.seen, typeName)
+		if pointed, ok := pointerStruct(field, tag, structs); ok && !slices.Contains(n.seen, pointed) {
+			inner := nesting{seen: append(slices.Clone(n.seen), pointed)}
+			innerTags, _, _ := structFields(structs[pointed], structs, validators, inner)
+			if slices.ContainsFunc(innerTags, func(tag TagInfo) bool { return tag.Bind != nil }) {
+				errs = append(errs, pointerError(field, pointed))
+			}
+		}
+
+		names := fieldNames(field)
+		for _, name := range names {
+			fields = append(fields, structField{name: n.path + name, expr: field.Type, flattened: nested, embedded: len(field.Names) == 0})
+		}
+
+		if nested {
+			inner := nesting{prefixes: n.prefixes, seen: append(slices.Clone(n.seen), typeName)}
+			if prefix, _, _ := lookupTag(tag, "prefix"); prefix != "" {
+				inner.prefixes = append([]string{prefix}, n.prefixes...)
+			}
+			for _, name := range names {
+				inner.path = n.path + name + "."
+				if len(field.Names) == 0 {
+					inner.path = n.path
+				}
+				innerTags, innerFields, innerErrs := structFields(structs[typeName], structs, validators, inner)
+				errs = append(errs, innerErrs...)
+				for _, tagInfo := range innerTags {
+					if len(field.Names) == 0 && shadowed(tagInfo.FieldName, n.path, declared) {
+						continue
+					}
+					tags = append(tags, tagInfo)
+				}
+				for _, f := range innerFields {
+					if len(field.Names) == 0 && shadowed(f.name, n.path, declared) {
+						continue
+					}
+					fields = append(fields, f)
+				}
+			}
+		}
+
+		tagInfo, ok, tagErrs := processField(field, validators)
+		errs = append(errs, tagErrs...)
+		if ok {
+			if n.path != "" || len(n.prefixes) > 0 {
+				nestTag(&tagInfo, n)
+			}
+			tags = append(tags, tagInfo)
+		}
+	}
+	return tags, fields, errs
+}
+
+// fieldNames returns the names of a struct field, the name of its type for embedded fields
+func fieldNames(field *ast.Field) []string {
+	if len(field.Names) == 0 {
+		return []string{embeddedName(field.Type)}
+	}
+	names := make([]string, len(field.Names))
+	for i, name := range field.Names {
+		names[i] = name.Name
+	}
+	return names
+}
+
+// embeddedName returns the field name of an embedded field of type expr, the name of the type
+// without its pointer, package or type arguments
+func embeddedName(expr ast.Expr) string {
+	switch e := expr.(type) {
+	case *ast.StarExpr:
+		return embeddedName(e.X)
+	case *ast.SelectorExpr:
+		return e.Sel.Name
+	case *ast.IndexExpr:
+		return embeddedName(e.X)
+	case *ast.IndexListExpr:
+		return embeddedName(e.X)
+	}
+	return types.ExprString(expr)
+}
+
+// nestedStruct returns the name of the struct type of the package a field holds, if its fields
+// are flattened into the enclosing struct: the field must hold a struct value, not a pointer,
+// and have no bind tag of its own.
+func nestedStruct(field *ast.Field, tag string, structs map[string]*ast.StructType) (string, bool) {
+	ident, ok := field.Type.(*ast.Ident)
+	bind, _, _ := lookupTag(tag, "bind")
+	if !ok || structs[ident.Name] == nil || bind != "" {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// pointerStruct returns the name of the struct type of the package a field points to, for fields
+// without a bind tag of their own, whose struct isn't flattened
+func pointerStruct(field *ast.Field, tag string, structs map[string]*ast.StructType) (string, bool) {
+	star, ok := field.Type.(*ast.StarExpr)
+	if !ok {
+		return "", false
+	}
+	ident, ok := star.X.(*ast.Ident)
+	bind, _, _ := lookupTag(tag, "bind")
+	if !ok || structs[ident.Name] == nil || bind != "" {
+		return "", false
+	}
+	return ident.Name, true
+}
+
+// pointerError returns the error of a field pointing to a struct type whose fields are bound
+func pointerError(field *ast.Field, typeName string) tagError {
+	if len(field.Names) == 0 {
+		return tagError{field.Type.Pos(), fmt.Errorf("embedded *%s isn't bound, as pointers to structs aren't flattened: embed %s instead", typeName, typeName)}
+	}
+	return tagError{field.Type.Pos(), fmt.Errorf("%s isn't bound, as pointers to structs aren't flattened: make it a %s", field.Names[0].Name, typeName)}
+}
+
+// shadowed reports whether a promoted field, named relative to path, is shadowed by a field
+// declared by the embedding struct
+func shadowed(name, path string, declared map[string]bool) bool {
+	name, _, _ = strings.Cut(strings.TrimPrefix(name, path), ".")
+	return declared[name]
+}
+
+// nestTag names a field of a nested struct by its selector from the outer struct and prefixes its
+// parameter name, as well as the sibling fields named by its validate rules. Body fields keep
+// their key, as the body isn't nested.
+func nestTag(tagInfo *TagInfo, n nesting) {
+	own := tagInfo.FieldName
+	tagInfo.FieldName = n.path + own
+
+	if b := tagInfo.Bind; b != nil {
+		if b.Type == "body" {
+			if b.Name == "" && tagInfo.JSONName == "" {
+				b.Name = own
+			}
+		} else {
+			if b.Name == "" {
+				b.Name = own
+			}
+			for _, prefix := range n.prefixes {
+				b.Name = applyPrefix(prefix, b.Name)
+			}
+		}
+	}
+
+	if v := tagInfo.Validate; v != nil && n.path != "" {
+		for _, field := range []*string{&v.EqField, &v.NeField, &v.GtField, &v.GteField, &v.LtField, &v.LteField} {
+			if *field != "" {
+				*field = n.path + *field
+			}
+		}
+		for _, fields := range [][]string{v.RequiredWith, v.RequiredWithout, v.ExcludedWith} {
+			for i := range fields {
+				fields[i] = n.path + fields[i]
+			}
+		}
+		for i := range v.RequiredIf {
+			v.RequiredIf[i].Field = n.path + v.RequiredIf[i].Field
+		}
+	}
+}
+
+// applyPrefix prefixes a parameter name. A prefix holding ... is a template the name replaces,
+// e.g. filter[...], and names already holding brackets keep them after their first segment, so
+// that range[from] nested in filter[...] becomes filter[range][from]. Other prefixes are prepended.
+func applyPrefix(prefix, name string) string {
+	template, suffix, ok := strings.Cut(prefix, "...")
+	if !ok {
+		return prefix + name
+	}
+	head, rest := name, ""
+	if i := strings.IndexByte(name, '['); i > 0 && strings.HasPrefix(suffix, "]") {
+		head, rest = name[:i], name[i:]
+	}
+	return template + head + suffix + rest
+}
+
+// parsedStruct is a struct of a parsed file along with all its fields, including those of the
+// structs it flattens
+type parsedStruct struct {
+	info   StructInfo
+	fields []structField
+}
+
+// nestStructs returns the structs with their nested fields, the fields holding structs that have a
+// generated validate function. Those are the structs with tags and the structs nesting them, found
+// until none is added, so that recursive types nest themselves. The validate function of a struct
+// takes a context when the struct, or a struct flattened into it, has a ValidateContext method, or
+// when it nests a struct whose does.
+func nestStructs(parsed []parsedStruct, hooks map[string]validateHooks) []StructInfo {
+	generated := make(map[string]bool)
+	for _, p := range parsed {
+		if len(p.info.Tags) > 0 {
+			generated[p.info.Name] = true
+		}
+	}
+	nested := make(map[string][]NestedField)
+	for changed := true; changed; {
+		changed = false
+		for _, p := range parsed {
+			nested[p.info.Name] = nestedFields(p.fields, generated)
+			if len(nested[p.info.Name]) > 0 && !generated[p.info.Name] {
+				generated[p.info.Name] = true
+				changed = true
+			}
+		}
+	}
+
+	flattened := make(map[string][]FlattenedField)
+	context := make(map[string]bool)
+	for name, h := range hooks {
+		context[name] = h.validateContext
+	}
+	for _, p := range parsed {
+		flattened[p.info.Name] = flattenedFields(p.fields, hooks)
+		for _, field := range flattened[p.info.Name] {
+			context[p.info.Name] = context[p.info.Name] || field.HasValidateContext
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, p := range parsed {
+			for _, field := range nested[p.info.Name] {
+				if context[field.Struct] && !context[p.info.Name] {
+					context[p.info.Name] = true
+					changed = true
+				}
+			}
+		}
+	}
+
+	structs := make([]StructInfo, len(parsed))
+	for i, p := range parsed {
+		s := p.info
+		s.Nested = nested[s.Name]
+		for j := range s.Nested {
+			s.Nested[j].Context = context[s.Nested[j].Struct]
+		}
+		s.Flattened = flattened[s.Name]
+		s.HasValidate, s.HasValidateContext = hooks[s.Name].validate, hooks[s.Name].validateContext
+		s.Context = context[s.Name]
+		structs[i] = s
+	}
+	return structs
+}
+
+// nestedFields returns the fields holding structs of generated, skipping the flattened ones
+func nestedFields(fields []structField, generated map[string]bool) []NestedField {
+	var nested []NestedField
+	for _, field := range fields {
+		if field.flattened {
+			continue
+		}
+		n, ok := nestedField(field.expr)
+		if ok && generated[n.Struct] {
+			n.FieldName = field.name
+			n.KeyUnderlyingType = field.keyType
+			nested = append(nested, n)
+		}
+	}
+	return nested
+}
+
+// flattenedFields returns the fields holding flattened structs that have validation methods. The
+// failures of the structs embedded in another are reported under the path of the embedding one, as
+// their fields are promoted.
+func flattenedFields(fields []structField, hooks map[string]validateHooks) []FlattenedField {
+	var flattened []FlattenedField
+	for _, field := range fields {
+		if !field.flattened {
+			continue
+		}
+		h := hooks[types.ExprString(field.expr)]
+		if !h.validate && !h.validateContext {
+			continue
+		}
+		path := field.name
+		if field.embedded {
+			path = path[:max(strings.LastIndexByte(path, '.'), 0)]
+		}
+		flattened = append(flattened, FlattenedField{FieldName: field.name, Path: path, HasValidate: h.validate, HasValidateContext: h.validateContext})
+	}
+	return flattened
+}
+
+// nestedField returns how a field of type expr holds structs of the package: as a value or a
+// pointer, directly or as the items of a slice, an array or a map
+func nestedField(expr ast.Expr) (NestedField, bool) {
+	var n NestedField
+	switch t := expr.(type) {
+	case *ast.ArrayType:
+		n.Kind, expr = NestedSlice, t.Elt
+	case *ast.MapType:
+		n.Kind, n.KeyType, expr = NestedMap, types.ExprString(t.Key), t.Value
+	}
+	if star, ok := expr.(*ast.StarExpr); ok {
+		n.Pointer, expr = true, star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return NestedField{}, false
+	}
+	n.Struct = ident.Name
+	return n, true
+}
+
+// packageStructs returns the struct types declared in files, by name
+func packageStructs(files []*ast.File) map[string]*ast.StructType {
+	structs := make(map[string]*ast.StructType)
+	for _, file := range files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if typeSpec, ok := n.(*ast.TypeSpec); ok {
+				if structType, ok := typeSpec.Type.(*ast.StructType); ok && typeSpec.TypeParams == nil {
+					structs[typeSpec.Name.Name] = structType
+				}
+			}
+			return true
+		})
+	}
+	return structs
+}
+
+// mergeFieldTypes adds the field types of another struct to fieldTypes, allocating it if needed
+func mergeFieldTypes(fieldTypes, more map[string]string) map[string]string {
+	if fieldTypes == nil {
+		fieldTypes = make(map[string]string)
+	}
+	maps.Copy(fieldTypes, more)
+	return fieldTypes
+}
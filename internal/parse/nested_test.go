@@ -0,0 +1,294 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePackageNestedStructs(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"pagination.go": `package api
+
+type Pagination struct {
+	Page  int ` + "`bind:\"query,default=1\" validate:\"min=1\"`" + `
+	Limit int ` + "`bind:\"query=limit\"`" + `
+}
+`,
+		"users.go": `package api
+
+type Range struct {
+	From int  ` + "`bind:\"query=from\"`" + `
+	To   *int ` + "`bind:\"query=to\" validate:\"gtfield=From\"`" + `
+}
+
+type Filter struct {
+	Status  string ` + "`bind:\"query=status\"`" + `
+	Created Range  ` + "`prefix:\"created[...]\"`" + `
+	Note    string ` + "`bind:\"body\" json:\"note\"`" + `
+}
+
+type ListUsers struct {
+	Pagination
+	Limit  int     ` + "`bind:\"header=X-Limit\"`" + `
+	Filter Filter  ` + "`prefix:\"filter[...]\"`" + `
+	Sort   Sort    ` + "`prefix:\"sort.\"`" + `
+	Parent *Filter
+}
+
+type Sort struct {
+	By string ` + "`bind:\"query\"`" + `
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	var listUsers *StructInfo
+	for i := range structs {
+		if structs[i].Name == "ListUsers" {
+			listUsers = &structs[i]
+		}
+	}
+	if listUsers == nil {
+		t.Fatalf("ParsePackage() = %+v, missing ListUsers", structs)
+	}
+
+	type field struct{ name, param string }
+	expected := []field{
+		{"Page", ""},
+		{"Limit", "X-Limit"},
+		{"Filter.Status", "filter[status]"},
+		{"Filter.Created.From", "filter[created][from]"},
+		{"Filter.Created.To", "filter[created][to]"},
+		{"Filter.Note", ""},
+		{"Sort.By", "sort.By"},
+	}
+	var got []field
+	for _, tag := range listUsers.Tags {
+		got = append(got, field{tag.FieldName, tag.Bind.Name})
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("fields = %v, want %v", got, expected)
+	}
+
+	if gtField := listUsers.Tags[4].Validate.GtField; gtField != "Filter.Created.From" {
+		t.Errorf("GtField = %q, want the nested sibling Filter.Created.From", gtField)
+	}
+	for name, typ := range map[string]string{"Page": "int", "Limit": "int", "Filter.Created.To": "*int", "Parent": "*Filter"} {
+		if listUsers.FieldTypes[name] != typ {
+			t.Errorf("FieldTypes[%q] = %q, want %q", name, listUsers.FieldTypes[name], typ)
+		}
+	}
+}
+
+func TestApplyPrefix(t *testing.T) {
+	tests := []struct {
+		prefix, name, want string
+	}{
+		{"filter.", "status", "filter.status"},
+		{"filter[...]", "status", "filter[status]"},
+		{"filter[...]", "created[from]", "filter[created][from]"},
+		{"filter.", "created[from]", "filter.created[from]"},
+		{"...-filter", "status", "status-filter"},
+	}
+
+	for _, tt := range tests {
+		if got := applyPrefix(tt.prefix, tt.name); got != tt.want {
+			t.Errorf("applyPrefix(%q, %q) = %q, want %q", tt.prefix, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParsePackageNestedValidation(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"order.go": `package api
+
+import "context"
+
+type Item struct {
+	Quantity int ` + "`validate:\"min=1\"`" + `
+}
+
+type Stock struct {
+	Warehouse string
+}
+
+func (s Stock) ValidateContext(ctx context.Context) error { return nil }
+
+type Node struct {
+	Children []*Node
+	Next     *Node
+	Item     *Item
+}
+
+type Order struct {
+	ID       string          ` + "`bind:\"query=id\"`" + `
+	Items    []Item          ` + "`bind:\"body\"`" + `
+	ByCode   map[string]*Item
+	Fixed    [2]Item
+	Tree     Node
+	Stock    []Stock
+	Tagged   Stock           ` + "`bind:\"body\"`" + `
+	Names    []string
+}
+
+type Catalog struct {
+	Orders map[int]Order
+	ByCode map[Code]*Order
+}
+
+type Code string
+
+type Plain struct {
+	Name string
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	got := make(map[string]StructInfo)
+	for _, s := range structs {
+		got[s.Name] = s
+	}
+	if _, ok := got["Plain"]; ok {
+		t.Errorf("ParsePackage() returned Plain, which has no tags nor nested structs")
+	}
+	if _, ok := got["Stock"]; ok {
+		t.Errorf("ParsePackage() returned Stock, which only has a ValidateContext method")
+	}
+
+	expected := map[string][]NestedField{
+		"Item": nil,
+		"Node": {
+			{FieldName: "Children", Struct: "Node", Kind: NestedSlice, Pointer: true},
+			{FieldName: "Next", Struct: "Node", Pointer: true},
+			{FieldName: "Item", Struct: "Item", Pointer: true},
+		},
+		"Order": {
+			{FieldName: "Items", Struct: "Item", Kind: NestedSlice},
+			{FieldName: "ByCode", Struct: "Item", Kind: NestedMap, Pointer: true, KeyType: "string", KeyUnderlyingType: "string"},
+			{FieldName: "Fixed", Struct: "Item", Kind: NestedSlice},
+			{FieldName: "Tree.Children", Struct: "Node", Kind: NestedSlice, Pointer: true},
+			{FieldName: "Tree.Next", Struct: "Node", Pointer: true},
+			{FieldName: "Tree.Item", Struct: "Item", Pointer: true},
+		},
+		"Catalog": {
+			{FieldName: "Orders", Struct: "Order", Kind: NestedMap, KeyType: "int", KeyUnderlyingType: "int"},
+			{FieldName: "ByCode", Struct: "Order", Kind: NestedMap, Pointer: true, KeyType: "Code", KeyUnderlyingType: "string"},
+		},
+	}
+	for name, nested := range expected {
+		s, ok := got[name]
+		if !ok {
+			t.Errorf("ParsePackage() = %+v, missing %s", structs, name)
+			continue
+		}
+		if !reflect.DeepEqual(s.Nested, nested) {
+			t.Errorf("%s.Nested = %+v, want %+v", name, s.Nested, nested)
+		}
+		if s.Context {
+			t.Errorf("%s.Context = true, want false", name)
+		}
+	}
+}
+
+func TestParsePackageNestedValidationContext(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"order.go": `package api
+
+import "context"
+
+type Item struct {
+	Quantity int ` + "`validate:\"min=1\"`" + `
+}
+
+func (i *Item) ValidateContext(ctx context.Context) error { return nil }
+
+type Order struct {
+	Items []Item
+}
+
+type Catalog struct {
+	Orders []*Order
+	Other  []Other
+}
+
+type Other struct {
+	Name string ` + "`validate:\"minlen=1\"`" + `
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	context := make(map[string]bool)
+	for _, s := range structs {
+		context[s.Name] = s.Context
+		if s.Name == "Catalog" && (!s.Nested[0].Context || s.Nested[1].Context) {
+			t.Errorf("Catalog.Nested = %+v, want a context for Orders only", s.Nested)
+		}
+	}
+	if want := map[string]bool{"Item": true, "Order": true, "Catalog": true, "Other": false}; !reflect.DeepEqual(context, want) {
+		t.Errorf("Context = %v, want %v", context, want)
+	}
+}
+
+func TestParsePackageFlattenedHooks(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"list.go": `package api
+
+import "context"
+
+type Pagination struct {
+	Page int ` + "`validate:\"min=1\"`" + `
+}
+
+func (p Pagination) Validate() error { return nil }
+
+type Range struct {
+	From int
+}
+
+func (r *Range) ValidateContext(ctx context.Context) error { return nil }
+
+type Filter struct {
+	Pagination
+	Range Range
+}
+
+type List struct {
+	Pagination
+	Filter Filter
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	// Structs embedded in a nested one report under its path
+	expected := []FlattenedField{
+		{FieldName: "Pagination", HasValidate: true},
+		{FieldName: "Filter.Pagination", Path: "Filter", HasValidate: true},
+		{FieldName: "Filter.Range", Path: "Filter.Range", HasValidateContext: true},
+	}
+	for _, s := range structs {
+		if s.Name != "List" {
+			continue
+		}
+		if !reflect.DeepEqual(s.Flattened, expected) {
+			t.Errorf("List.Flattened = %+v, want %+v", s.Flattened, expected)
+		}
+		if !s.Context {
+			t.Errorf("List.Context = false, want true for the ValidateContext method of Filter.Range")
+		}
+	}
+}
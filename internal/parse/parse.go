@@ -6,45 +6,203 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"io/fs"
-	"os"
+	"maps"
+	"math"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
+	"time"
 )
 
 // TagInfo represents the extracted tag information
+// JSONName is the key from the field's json tag, if any, and is used when binding from a JSON body.
+// FieldType is the type of the field as written in the package, with the types of other packages
+// qualified by their package name, and TypeImports the import paths of these packages, e.g. time for
+// time.Time. ElemType is the type of the values bound to the field: the type it points to, the item
+// type of slices, or FieldType. UnderlyingType is FieldType with named types replaced by their
+// underlying types, such as *int for a *UserID, which the generator dispatches on; time.Time,
+// time.Duration and other named types that aren't basic types, pointers or slices keep their name.
+// Types are resolved by type-checking the package, and taken as written if that fails.
+// TextUnmarshaler is set for fields bound from request parameters whose type, the type it points to
+// or its item type for slices implements encoding.TextUnmarshaler, and are bound with UnmarshalText.
 type TagInfo struct {
-	FieldName string
-	FieldType string
-	Bind      *BindTag
-	Validate  *ValidateTag
+	FieldName       string
+	FieldType       string
+	ElemType        string
+	UnderlyingType  string
+	JSONName        string
+	Bind            *BindTag
+	Validate        *ValidateTag
+	TypeImports     []string
+	TextUnmarshaler bool
 }
 
 // StructInfo represents the parsed struct information
+// FieldTypes maps the name of every named field of the struct, tagged or not, to its type, and
+// UnderlyingTypes to its underlying type, so that rules referring to other fields can be resolved.
+// HasValidate and HasValidateContext tell whether the struct has a Validate() error or a
+// ValidateContext(context.Context) error method, with a value or pointer receiver.
+// Nested lists the fields holding structs validated by their own generated function, Flattened
+// the fields holding flattened structs with validation methods of their own, and Context tells
+// whether the validation of one of them, or the struct's own, takes a context.
 type StructInfo struct {
-	Name string
-	Tags []TagInfo
+	Name               string
+	Tags               []TagInfo
+	FieldTypes         map[string]string
+	UnderlyingTypes    map[string]string
+	HasValidate        bool
+	HasValidateContext bool
+	Nested             []NestedField
+	Flattened          []FlattenedField
+	Context            bool
+}
+
+// NestedKind is how a field holds the structs it nests
+type NestedKind int
+
+// Kinds of nested fields
+const (
+	NestedStruct NestedKind = iota
+	NestedSlice
+	NestedMap
+)
+
+// NestedField is a field holding structs of the package that have a generated validate function,
+// named Struct: a struct, a slice or array of them, or a map with keys of KeyType to them, whose
+// underlying type is KeyUnderlyingType once resolved. Pointer is set when the struct or the items are pointers,
+// and Context when the validate function of Struct takes a context.
+type NestedField struct {
+	FieldName         string
+	Struct            string
+	Kind              NestedKind
+	Pointer           bool
+	KeyType           string
+	KeyUnderlyingType string
+	Context           bool
+}
+
+// FlattenedField is a field holding a struct of the package whose fields are flattened into the
+// outer struct, and that has a Validate() error or ValidateContext(context.Context) error method,
+// as told by HasValidate and HasValidateContext. FieldName is the selector of the field from the
+// outer struct, and Path the one the failures of its methods are reported under, empty for structs
+// embedded in the outer struct, whose fields are promoted.
+type FlattenedField struct {
+	FieldName          string
+	Path               string
+	HasValidate        bool
+	HasValidateContext bool
 }
 
 // BindTag represents bind tag information
-// Type refers to the one of three possible options:
+// Type refers to the one of the following options:
 // - Header: http header params
 // - Path: Path parameters, e.g., in /user/{id}, {id} would be the path parameter
 // - Query: Query params from the URI
+// - Body: JSON request body. By default the field is decoded from the body key named by its
+// json tag (or the field name); with the whole option the entire body is decoded into the field.
+// - Form: url-encoded or multipart form values, bound to scalar or slice fields
+// - File: uploaded multipart files, bound to *multipart.FileHeader or []*multipart.FileHeader fields
+// - Cookie: request cookies, bound either as the cookie value or as the whole *http.Cookie
+// Name is the request parameter name given after the type, e.g. query=user_id. When empty the
+// generator falls back to the Go field name (or the json tag name for body fields).
 // Required is an optional tag, and is used to specify that a parameter must be present
 // in order for the parameter validation to pass.
+// Whole and AllowUnknown only apply to body bindings. MaxBytes is the request body size limit
+// for body bindings and the per-file size limit for file bindings, 0 meaning no explicit limit.
+// Accept lists the media types allowed for file bindings, e.g. image/png or image/*.
+// CommaSeparated is set by explode=false on slice fields: each value is a comma-separated list
+// (OpenAPI style), rather than the default of one item per repeated parameter.
+// Default is the value used when the parameter is missing, nil if not specified. It is kept as
+// written in the tag, escapes included, and checked against the field type by the generator; slice
+// defaults list their items separated by |, to be split with SplitList, and others are unescaped.
+// Layout is the format of time.Time fields, either the name of a built-in layout such as date or
+// unix, or a Go time layout. Empty means RFC 3339.
 type BindTag struct {
-	Type     string
-	Required bool
+	Type           string
+	Name           string
+	Required       bool
+	Whole          bool
+	AllowUnknown   bool
+	MaxBytes       int64
+	Accept         []string
+	CommaSeparated bool
+	Default        *string
+	Layout         string
 }
 
-// ValidateTag represents validate tag information for min and max validation on incoming int values
-// Min specifies the minimum value (inclusive), nil if not specified
-// Max specifies the maximum value (inclusive), nil if not specified
+// ValidateTag represents validate tag information
+// Min and Max are the inclusive bounds of numbers, durations and times, or of the number of items
+// of slices, given as min/gte and max/lte. Gt and Lt are the exclusive bounds. Each is nil if not specified.
+// MinLen, MaxLen and Len bound the length of strings in runes, nil if not specified
+// Pattern is a regular expression strings must match, empty if not specified
+// OneOf lists the values a string or number is allowed to take, given as oneof=a|b|c
+// Format names a built-in string format, such as email or uuid, empty if not specified
+// EqField, NeField, GtField, GteField, LtField and LteField name a sibling field the field is compared
+// with, empty if not specified
+// RequiredWith and RequiredWithout list sibling fields that make the field required when any of them is
+// set or not set, RequiredIf the conditions on sibling values that together make it required, and
+// ExcludedWith the sibling fields that must not be set along with it. Lists are separated by |.
+// Validators lists the custom validators named by the tag, in the order they are written.
+// Whether a rule applies to the field's type, and whether the fields it names exist, is checked by the generator.
 type ValidateTag struct {
-	Min *int
-	Max *int
+	Min             *Bound
+	Max             *Bound
+	Gt              *Bound
+	Lt              *Bound
+	MinLen          *int
+	MaxLen          *int
+	Len             *int
+	Pattern         string
+	OneOf           []string
+	Format          string
+	EqField         string
+	NeField         string
+	GtField         string
+	GteField        string
+	LtField         string
+	LteField        string
+	RequiredWith    []string
+	RequiredWithout []string
+	RequiredIf      []Condition
+	ExcludedWith    []string
+	Validators      []Validator
+}
+
+// Condition is a condition of a required_if rule, holding when the sibling Field equals Value.
+// It's written as Field:value.
+type Condition struct {
+	Field string
+	Value string
+}
+
+// BoundKind is the kind of value a bound was written as
+type BoundKind int
+
+// Kinds of bound values
+const (
+	IntBound BoundKind = iota
+	UintBound
+	FloatBound
+	DurationBound
+	TimeBound
+)
+
+// Bound is a typed bound of a validate rule
+// Kind tells which of Int, Uint, Float, Duration or Time holds the value: integers are IntBound
+// unless they only fit in a uint64, other numbers FloatBound, Go durations such as 24h DurationBound,
+// and RFC 3339 times or dates TimeBound.
+// Raw is the bound as written in the tag.
+type Bound struct {
+	Kind     BoundKind
+	Int      int64
+	Uint     uint64
+	Float    float64
+	Duration time.Duration
+	Time     time.Time
+	Raw      string
 }
 
 // ParseStruct parses a Go struct source code and extracts tag information
@@ -54,156 +212,339 @@ func ParseStruct(source string) (StructInfo, error) {
 	if err != nil {
 		return StructInfo{}, fmt.Errorf("failed to parse source: %w", err)
 	}
+	validators, err := findValidators(fset, ".", []*ast.File{file})
+	if err != nil {
+		return StructInfo{}, err
+	}
 
 	var structInfo StructInfo
-	var tags []TagInfo
+	structs := packageStructs([]*ast.File{file})
+	hooks := findHooks(file)
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		typeSpec, ok := n.(*ast.TypeSpec)
-		if !ok {
-			return true
-		}
-		structType, ok := typeSpec.Type.(*ast.StructType)
-		if !ok {
-			return true
-		}
-		structInfo.Name = typeSpec.Name.Name
-		for _, field := range structType.Fields.List {
-			if tagInfo, ok := processField(field); ok {
-				tags = append(tags, tagInfo)
-			}
-		}
-		return true
-	})
-
-	structInfo.Tags = tags
+	// The tags and field types of all the structs are merged into the last one
+	resolver, err := newTypeResolver(fset, ".", []*ast.File{file})
+	if err != nil {
+		return StructInfo{}, err
+	}
+	parsed, errs := fileStructs(file, structs, validators, resolver)
+	if diags := diagnostics(fset, errs); len(diags) > 0 {
+		return StructInfo{}, fmt.Errorf("malformed tag at %s", diags[0])
+	}
+	for _, s := range nestStructs(parsed, hooks) {
+		structInfo.Tags = append(structInfo.Tags, s.Tags...)
+		structInfo.FieldTypes = mergeFieldTypes(structInfo.FieldTypes, s.FieldTypes)
+		structInfo.UnderlyingTypes = mergeFieldTypes(structInfo.UnderlyingTypes, s.UnderlyingTypes)
+		structInfo.Name, structInfo.Nested, structInfo.Flattened = s.Name, s.Nested, s.Flattened
+		structInfo.HasValidate, structInfo.HasValidateContext, structInfo.Context = s.HasValidate, s.HasValidateContext, s.Context
+	}
 	return structInfo, nil
 }
 
-// processField processes a single struct field and extracts tag information
-func processField(field *ast.Field) (TagInfo, bool) {
+// processField processes a single struct field and extracts tag information. Fields with
+// malformed bind or validate tags are skipped, with the errors of their tags.
+func processField(field *ast.Field, validators map[string]Validator) (TagInfo, bool, []tagError) {
 	if field.Tag == nil {
-		return TagInfo{}, false
+		return TagInfo{}, false, nil
+	}
+	tag, err := fieldTag(field)
+	if err != nil {
+		return TagInfo{}, false, []tagError{newTagError(field, "", err)}
 	}
-	tag := strings.Trim(field.Tag.Value, "`")
 	tagInfo := TagInfo{}
 
 	if len(field.Names) > 0 {
 		tagInfo.FieldName = field.Names[0].Name
+	} else if field.Type != nil {
+		tagInfo.FieldName = embeddedName(field.Type)
 	}
 
 	// Set field type
-	if ident, ok := field.Type.(*ast.Ident); ok {
-		tagInfo.FieldType = ident.Name
+	if field.Type != nil {
+		tagInfo.FieldType = types.ExprString(field.Type)
+	}
+
+	// The syntax of the whole tag is checked by looking up no key, so that a malformed tag is
+	// reported once rather than for each key
+	if _, _, err := lookupTag(tag, ""); err != nil {
+		return TagInfo{}, false, []tagError{newTagError(field, "", err)}
+	}
+
+	var errs []tagError
+	jsonStr, _, err := lookupTag(tag, "json")
+	if err != nil {
+		errs = append(errs, newTagError(field, "json", err))
+	}
+	if jsonStr != "" {
+		name, _, _ := strings.Cut(jsonStr, ",")
+		tagInfo.JSONName = name
 	}
 
-	if bindStr := extractTagValue(tag, "bind"); bindStr != "" {
+	bindStr, _, err := lookupTag(tag, "bind")
+	if err != nil {
+		errs = append(errs, newTagError(field, "bind", err))
+	}
+	if bindStr != "" {
 		bindTag, err := parseBindTag(bindStr)
 		if err != nil {
-			// Skip invalid bind tags
-			return TagInfo{}, false
+			errs = append(errs, newTagError(field, "bind", err))
 		}
 		tagInfo.Bind = bindTag
 	}
 
-	if validateStr := extractTagValue(tag, "validate"); validateStr != "" {
-		validateTag, err := parseValidateTag(validateStr)
+	validateStr, _, err := lookupTag(tag, "validate")
+	if err != nil {
+		errs = append(errs, newTagError(field, "validate", err))
+	}
+	if validateStr != "" {
+		validateTag, err := parseValidateTag(validateStr, validators)
 		if err != nil {
-			// Skip invalid validate tags
-			return TagInfo{}, false
+			errs = append(errs, newTagError(field, "validate", err))
 		}
 		tagInfo.Validate = validateTag
 	}
 
+	if len(errs) > 0 {
+		return TagInfo{}, false, errs
+	}
 	if tagInfo.Bind != nil || tagInfo.Validate != nil {
-		return tagInfo, true
+		return tagInfo, true, nil
 	}
-	return TagInfo{}, false
+	return TagInfo{}, false, nil
 }
 
-// extractTagValue extracts the value for a specific tag key from the tag string
-func extractTagValue(tagStr, key string) string {
-	// Simple parsing - in a real implementation you'd want more robust parsing
-	// Tags are in format: `key:"value" other:"value"`
-	parts := strings.Fields(tagStr)
-	for _, part := range parts {
-		if strings.HasPrefix(part, key+":") {
-			// Extract value between quotes
-			if colonIdx := strings.Index(part, ":"); colonIdx != -1 {
-				value := part[colonIdx+1:]
-				if len(value) >= 2 && value[0] == '"' && value[len(value)-1] == '"' {
-					return value[1 : len(value)-1]
-				}
-			}
-		}
-	}
-	return ""
+// bindTypes are the sources a field can be bound from
+var bindTypes = []string{"header", "path", "query", "body", "form", "file", "cookie"}
+
+// bindOptions are the options taken by each bind type, suggested for misspelt options
+var bindOptions = map[string][]string{
+	"header": {"required", "explode", "default", "layout"},
+	"path":   {"required", "explode", "default", "layout"},
+	"query":  {"required", "explode", "default", "layout"},
+	"form":   {"required", "explode", "default", "layout"},
+	"cookie": {"required", "explode", "default", "layout"},
+	"body":   {"required", "whole", "allowunknown", "maxbytes"},
+	"file":   {"required", "maxbytes", "accept"},
 }
 
-// parseBindTag parses the bind tag value
+// parseBindTag parses the bind tag value. Options are separated by commas, and the items of accept
+// by |, either of which is taken literally when escaped by a backslash.
 func parseBindTag(value string) (*BindTag, error) {
-	parts := strings.Split(value, ",")
+	parts := splitEscaped(value, ',')
 	if len(parts) == 0 || parts[0] == "" {
 		return nil, fmt.Errorf("empty bind tag")
 	}
 
 	bindTag := &BindTag{}
 
-	// First part is the type
-	bindTag.Type = strings.TrimSpace(parts[0])
-	switch bindTag.Type {
-	case "header", "path", "query":
-		// Valid
-	default:
-		return nil, fmt.Errorf("invalid bind type: %s", bindTag.Type)
+	// First part is the type, optionally followed by the parameter name
+	bindType, name, hasName := strings.Cut(strings.TrimSpace(parts[0]), "=")
+	bindTag.Type = strings.TrimSpace(bindType)
+	bindTag.Name = Unescape(strings.TrimSpace(name))
+	if hasName && bindTag.Name == "" {
+		return nil, fmt.Errorf("empty parameter name for bind type: %s", bindTag.Type)
+	}
+	if !slices.Contains(bindTypes, bindTag.Type) {
+		return nil, fmt.Errorf("invalid bind type %q%s", bindTag.Type, suggest(bindTag.Type, bindTypes))
 	}
 
 	// Required is implicit: present means required, absent means optional
 	bindTag.Required = false
-	if len(parts) > 1 {
-		requiredStr := strings.TrimSpace(parts[1])
-		if requiredStr == "required" {
+	for _, part := range parts[1:] {
+		option := strings.TrimSpace(part)
+		if option == "required" {
 			bindTag.Required = true
-		} else {
-			return nil, fmt.Errorf("invalid option: %s", requiredStr)
+			continue
 		}
+		isBody, isFile := bindTag.Type == "body", bindTag.Type == "file"
+		switch {
+		case !isBody && !isFile && (option == "explode=true" || option == "explode=false"):
+			bindTag.CommaSeparated = option == "explode=false"
+		case !isBody && !isFile && strings.HasPrefix(option, "default="):
+			def := strings.TrimPrefix(option, "default=")
+			if def == "" {
+				return nil, fmt.Errorf("empty default value")
+			}
+			bindTag.Default = &def
+		case !isBody && !isFile && strings.HasPrefix(option, "layout="):
+			bindTag.Layout = Unescape(strings.TrimPrefix(option, "layout="))
+			if bindTag.Layout == "" {
+				return nil, fmt.Errorf("empty layout")
+			}
+		case isBody && option == "whole":
+			bindTag.Whole = true
+		case isBody && option == "allowunknown":
+			bindTag.AllowUnknown = true
+		case (isBody || isFile) && strings.HasPrefix(option, "maxbytes="):
+			maxBytes, err := strconv.ParseInt(strings.TrimPrefix(option, "maxbytes="), 10, 64)
+			if err != nil || maxBytes <= 0 {
+				return nil, fmt.Errorf("invalid maxbytes value: %s", option)
+			}
+			bindTag.MaxBytes = maxBytes
+		case isFile && strings.HasPrefix(option, "accept="):
+			for _, mediaType := range SplitList(strings.TrimPrefix(option, "accept=")) {
+				if !strings.Contains(mediaType, "/") {
+					return nil, fmt.Errorf("invalid accept media type: %s", mediaType)
+				}
+				bindTag.Accept = append(bindTag.Accept, mediaType)
+			}
+		default:
+			name, _, _ := strings.Cut(option, "=")
+			return nil, fmt.Errorf("invalid option %q for bind type %s%s", option, bindTag.Type, suggest(name, bindOptions[bindTag.Type]))
+		}
+	}
+
+	if bindTag.Whole && bindTag.Name != "" {
+		return nil, fmt.Errorf("whole body binding can't have a parameter name")
+	}
+	if bindTag.Required && bindTag.Default != nil {
+		return nil, fmt.Errorf("required parameter can't have a default")
 	}
 
 	return bindTag, nil
 }
 
-// parseValidateTag parses the validate tag value for min and max validation
-func parseValidateTag(value string) (*ValidateTag, error) {
-	parts := strings.Split(value, ",")
+// parseValidateTag parses the validate tag value
+// Rules written as a bare name are the custom validators of that name. Rules are separated by
+// commas, and the items of lists by |, either of which is taken literally when escaped by a
+// backslash. Patterns aren't lists, so only their commas are unescaped: \| is left to match a
+// literal | in the regular expression.
+func parseValidateTag(value string, validators map[string]Validator) (*ValidateTag, error) {
+	parts := splitEscaped(value, ',')
 	validateTag := &ValidateTag{}
 
 	for _, part := range parts {
 		part = strings.TrimSpace(part)
-		if after, ok := strings.CutPrefix(part, "min="); ok {
-			minStr := after
-			minVal, err := strconv.Atoi(minStr)
+		rule, arg, _ := strings.Cut(part, "=")
+		switch rule {
+		case "min", "gte", "max", "lte", "gt", "lt":
+			bound, err := parseBound(arg)
 			if err != nil {
-				return nil, fmt.Errorf("invalid min value: %s", minStr)
+				return nil, fmt.Errorf("invalid %s value: %s", rule, arg)
 			}
-			validateTag.Min = &minVal
-		} else if after, ok = strings.CutPrefix(part, "max="); ok {
-			maxStr := after
-			maxVal, err := strconv.Atoi(maxStr)
-			if err != nil {
-				return nil, fmt.Errorf("invalid max value: %s", maxStr)
+			// gte and lte are the same inclusive bounds as min and max
+			var target **Bound
+			switch rule {
+			case "min", "gte":
+				target = &validateTag.Min
+			case "max", "lte":
+				target = &validateTag.Max
+			case "gt":
+				target = &validateTag.Gt
+			default:
+				target = &validateTag.Lt
+			}
+			if *target != nil {
+				return nil, fmt.Errorf("duplicate %s bound: %s", rule, arg)
+			}
+			*target = bound
+		case "minlen", "maxlen", "len":
+			val, err := strconv.Atoi(arg)
+			if err != nil || val < 0 {
+				return nil, fmt.Errorf("invalid %s value: %s", rule, arg)
+			}
+			switch rule {
+			case "minlen":
+				validateTag.MinLen = &val
+			case "maxlen":
+				validateTag.MaxLen = &val
+			default:
+				validateTag.Len = &val
+			}
+		case "pattern":
+			if arg == "" {
+				return nil, fmt.Errorf("empty pattern")
+			}
+			validateTag.Pattern = strings.ReplaceAll(arg, `\,`, ",")
+		case "oneof":
+			if arg == "" {
+				return nil, fmt.Errorf("empty oneof values")
 			}
-			validateTag.Max = &maxVal
-		} else {
-			return nil, fmt.Errorf("unsupported validation rule: %s", part)
+			validateTag.OneOf = SplitList(arg)
+		case "format":
+			if arg == "" {
+				return nil, fmt.Errorf("empty format")
+			}
+			validateTag.Format = Unescape(arg)
+		case "eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield":
+			if !token.IsIdentifier(arg) {
+				return nil, fmt.Errorf("invalid %s field: %q", rule, arg)
+			}
+			switch rule {
+			case "eqfield":
+				validateTag.EqField = arg
+			case "nefield":
+				validateTag.NeField = arg
+			case "gtfield":
+				validateTag.GtField = arg
+			case "gtefield":
+				validateTag.GteField = arg
+			case "ltfield":
+				validateTag.LtField = arg
+			default:
+				validateTag.LteField = arg
+			}
+		case "required_with", "required_without", "excluded_with":
+			fields := SplitList(arg)
+			for _, field := range fields {
+				if !token.IsIdentifier(field) {
+					return nil, fmt.Errorf("invalid %s field: %q", rule, field)
+				}
+			}
+			switch rule {
+			case "required_with":
+				validateTag.RequiredWith = fields
+			case "required_without":
+				validateTag.RequiredWithout = fields
+			default:
+				validateTag.ExcludedWith = fields
+			}
+		case "required_if":
+			for _, cond := range SplitList(arg) {
+				field, value, ok := strings.Cut(cond, ":")
+				if !ok || !token.IsIdentifier(field) {
+					return nil, fmt.Errorf("invalid required_if condition: %q, want Field:value", cond)
+				}
+				validateTag.RequiredIf = append(validateTag.RequiredIf, Condition{Field: field, Value: value})
+			}
+		default:
+			validator, ok := validators[part]
+			if !ok {
+				return nil, fmt.Errorf("unsupported validation rule %q%s", part, suggest(rule, append(slices.Clone(builtinRules), slices.Sorted(maps.Keys(validators))...)))
+			}
+			validateTag.Validators = append(validateTag.Validators, validator)
 		}
 	}
 
 	return validateTag, nil
 }
 
-// ParsePackage parses all Go structs with bind or validate tags in the given directory
-func ParsePackage(dir string) ([]StructInfo, string, error) {
-	var structs []StructInfo
+// parseBound parses the value of a bound rule, trying each kind of bound in turn
+func parseBound(value string) (*Bound, error) {
+	bound := &Bound{Raw: value}
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		bound.Kind, bound.Int = IntBound, i
+	} else if u, err := strconv.ParseUint(value, 10, 64); err == nil {
+		bound.Kind, bound.Uint = UintBound, u
+	} else if f, err := strconv.ParseFloat(value, 64); err == nil && !math.IsInf(f, 0) && !math.IsNaN(f) {
+		bound.Kind, bound.Float = FloatBound, f
+	} else if d, err := time.ParseDuration(value); err == nil {
+		bound.Kind, bound.Duration = DurationBound, d
+	} else if t, err := time.Parse(time.RFC3339, value); err == nil {
+		bound.Kind, bound.Time = TimeBound, t
+	} else if t, err := time.Parse(time.DateOnly, value); err == nil {
+		bound.Kind, bound.Time = TimeBound, t
+	} else {
+		return nil, fmt.Errorf("invalid bound: %s", value)
+	}
+	return bound, nil
+}
+
+// ParsePackage parses all Go structs with bind or validate tags in the given directory, returned in
+// source order, files by name. Fields with malformed tags are left out of the structs, and reported
+// by the returned diagnostics.
+func ParsePackage(dir string) ([]StructInfo, string, []Diagnostic, error) {
+	fset := token.NewFileSet()
+	var files []*ast.File
 	var pkgName string
 	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
@@ -215,36 +556,63 @@ func ParsePackage(dir string) ([]StructInfo, string, error) {
 		if !strings.HasSuffix(path, ".go") {
 			return nil
 		}
-		fileStructs, filePkgName, err := parseFile(path)
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
 		if err != nil {
 			return err
 		}
 		if pkgName == "" {
-			pkgName = filePkgName
-		} else if pkgName != filePkgName {
-			return fmt.Errorf("inconsistent package names: %s and %s", pkgName, filePkgName)
+			pkgName = file.Name.Name
+		} else if pkgName != file.Name.Name {
+			return fmt.Errorf("inconsistent package names: %s and %s", pkgName, file.Name.Name)
 		}
-		structs = append(structs, fileStructs...)
+		files = append(files, file)
 		return nil
 	})
-	return structs, pkgName, err
-}
+	if err != nil {
+		return nil, "", nil, err
+	}
+	if len(files) == 0 {
+		return nil, "", nil, nil
+	}
 
-// parseFile parses a single Go file and extracts structs with tags
-func parseFile(path string) ([]StructInfo, string, error) {
-	src, err := os.ReadFile(path)
+	// Validators and methods can be declared in another file than the structs using them
+	validators, err := findValidators(fset, dir, files)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
 	}
-	fset := token.NewFileSet()
-	file, err := parser.ParseFile(fset, path, src, parser.ParseComments)
+	hooks := make(map[string]validateHooks)
+	structTypes := packageStructs(files)
+	resolver, err := newTypeResolver(fset, dir, files)
 	if err != nil {
-		return nil, "", err
+		return nil, "", nil, err
+	}
+	var all []parsedStruct
+	var errs []tagError
+	for _, file := range files {
+		for name, h := range findHooks(file) {
+			hooks[name] = validateHooks{hooks[name].validate || h.validate, hooks[name].validateContext || h.validateContext}
+		}
+		structs, tagErrs := fileStructs(file, structTypes, validators, resolver)
+		all = append(all, structs...)
+		errs = append(errs, tagErrs...)
 	}
 
-	pkgName := file.Name.Name
-
+	// Structs without tags are only generated for the structs they nest
 	var structs []StructInfo
+	for _, s := range nestStructs(all, hooks) {
+		if len(s.Tags) > 0 || len(s.Nested) > 0 {
+			structs = append(structs, s)
+		}
+	}
+	return structs, pkgName, diagnostics(fset, errs), nil
+}
+
+// fileStructs extracts the structs of a parsed file, flattening the structs of the package they
+// embed or nest, and resolving the packages and methods of their field types. Their nested fields
+// are found by nestStructs. The errors of malformed tags are returned along with them.
+func fileStructs(file *ast.File, structTypes map[string]*ast.StructType, validators map[string]Validator, resolver *typeResolver) ([]parsedStruct, []tagError) {
+	var structs []parsedStruct
+	var errs []tagError
 	ast.Inspect(file, func(n ast.Node) bool {
 		typeSpec, ok := n.(*ast.TypeSpec)
 		if !ok {
@@ -254,16 +622,56 @@ func parseFile(path string) ([]StructInfo, string, error) {
 		if !ok {
 			return true
 		}
-		var tags []TagInfo
-		for _, field := range structType.Fields.List {
-			if tagInfo, ok := processField(field); ok {
-				tags = append(tags, tagInfo)
-			}
-		}
-		if len(tags) > 0 {
-			structs = append(structs, StructInfo{Name: typeSpec.Name.Name, Tags: tags})
-		}
+		tags, fields, tagErrs := structFields(structType, structTypes, validators, nesting{seen: []string{typeSpec.Name.Name}})
+		errs = append(errs, tagErrs...)
+		resolver.resolveTypes(file, tags, fields)
+		resolver.resolveKeys(fields)
+		info := StructInfo{Name: typeSpec.Name.Name, Tags: tags}
+		info.FieldTypes, info.UnderlyingTypes = resolver.fieldTypes(fields)
+		structs = append(structs, parsedStruct{info, fields})
 		return true
 	})
-	return structs, pkgName, nil
+	return structs, errs
+}
+
+// validateHooks records which struct-level validation methods a type has
+type validateHooks struct {
+	validate        bool
+	validateContext bool
+}
+
+// findHooks returns the Validate() error and ValidateContext(context.Context) error methods
+// declared in a file, by receiver type name
+func findHooks(file *ast.File) map[string]validateHooks {
+	hooks := make(map[string]validateHooks)
+	for _, decl := range file.Decls {
+		fn, ok := decl.(*ast.FuncDecl)
+		if !ok || fn.Recv == nil || len(fn.Recv.List) != 1 {
+			continue
+		}
+		results := fn.Type.Results
+		if results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 || types.ExprString(results.List[0].Type) != "error" {
+			continue
+		}
+		recv := fn.Recv.List[0].Type
+		if star, ok := recv.(*ast.StarExpr); ok {
+			recv = star.X
+		}
+		ident, ok := recv.(*ast.Ident)
+		if !ok {
+			continue
+		}
+		params := fn.Type.Params.List
+		h := hooks[ident.Name]
+		switch {
+		case fn.Name.Name == "Validate" && len(params) == 0:
+			h.validate = true
+		case fn.Name.Name == "ValidateContext" && len(params) == 1 && len(params[0].Names) <= 1 && types.ExprString(params[0].Type) == "context.Context":
+			h.validateContext = true
+		default:
+			continue
+		}
+		hooks[ident.Name] = h
+	}
+	return hooks
 }
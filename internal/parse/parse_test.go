@@ -4,7 +4,9 @@ import (
 	"go/ast"
 	"go/token"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
 func TestParseStruct(t *testing.T) {
@@ -110,7 +112,7 @@ type User struct {
 					{
 						FieldName: "Name",
 						FieldType: "string",
-						Validate:  &ValidateTag{Min: &[]int{10}[0]},
+						Validate:  &ValidateTag{Min: intBound(10)},
 					},
 				},
 			},
@@ -133,8 +135,8 @@ type User struct {
 							Required: true,
 						},
 						Validate: &ValidateTag{
-							Min: &[]int{1}[0],
-							Max: &[]int{100}[0],
+							Min: intBound(1),
+							Max: intBound(100),
 						},
 					},
 				},
@@ -198,6 +200,91 @@ type User struct {
 	}
 }
 
+func TestParseStructFieldTypes(t *testing.T) {
+	source := `package main
+
+type Params struct {
+	Flag  bool ` + "`" + `bind:"query"` + "`" + `
+	Count uint16 ` + "`" + `bind:"query"` + "`" + `
+	Raw   []byte ` + "`" + `bind:"header"` + "`" + `
+	Tags  []string ` + "`" + `bind:"form"` + "`" + `
+	Page  *int ` + "`" + `bind:"query"` + "`" + `
+}`
+
+	result, err := ParseStruct(source)
+	if err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	expected := []string{"bool", "uint16", "[]byte", "[]string", "*int"}
+	if len(result.Tags) != len(expected) {
+		t.Fatalf("ParseStruct() got %d results, want %d", len(result.Tags), len(expected))
+	}
+	for i, fieldType := range expected {
+		if result.Tags[i].FieldType != fieldType {
+			t.Errorf("FieldType[%d] = %v, want %v", i, result.Tags[i].FieldType, fieldType)
+		}
+	}
+}
+
+func TestParseStructSiblingFieldTypes(t *testing.T) {
+	source := `package main
+
+type Query struct {
+	From, To int ` + "`" + `validate:"min=0"` + "`" + `
+	Mode string
+}`
+
+	result, err := ParseStruct(source)
+	if err != nil {
+		t.Fatalf("ParseStruct() error = %v", err)
+	}
+
+	expected := map[string]string{"From": "int", "To": "int", "Mode": "string"}
+	if !reflect.DeepEqual(result.FieldTypes, expected) {
+		t.Errorf("FieldTypes = %v, want %v", result.FieldTypes, expected)
+	}
+}
+
+func TestParseStructHooks(t *testing.T) {
+	tests := []struct {
+		name                   string
+		methods                string
+		wantValidate, wantCtxt bool
+	}{
+		{"none", "", false, false},
+		{"validate with pointer receiver", "func (o *Order) Validate() error { return nil }", true, false},
+		{"validate context with value receiver", "func (o Order) ValidateContext(ctx context.Context) error { return nil }", false, true},
+		{"both", "func (Order) Validate() error { return nil }\nfunc (*Order) ValidateContext(context.Context) error { return nil }", true, true},
+		{"other signature", "func (o Order) Validate(strict bool) error { return nil }\nfunc (o Order) ValidateContext() error { return nil }", false, false},
+		{"other result", "func (o Order) Validate() bool { return true }", false, false},
+		{"other type", "func (o Item) Validate() error { return nil }", false, false},
+		{"function", "func Validate() error { return nil }", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			source := `package main
+
+import "context"
+
+type Order struct {
+	Qty int ` + "`" + `validate:"min=1"` + "`" + `
+}
+
+` + tt.methods
+
+			result, err := ParseStruct(source)
+			if err != nil {
+				t.Fatalf("ParseStruct() error = %v", err)
+			}
+			if result.HasValidate != tt.wantValidate || result.HasValidateContext != tt.wantCtxt {
+				t.Errorf("HasValidate, HasValidateContext = %v, %v, want %v, %v", result.HasValidate, result.HasValidateContext, tt.wantValidate, tt.wantCtxt)
+			}
+		})
+	}
+}
+
 func TestParseBindTag(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -257,6 +344,163 @@ func TestParseBindTag(t *testing.T) {
 				Required: false,
 			},
 		},
+		{
+			name:  "body required",
+			input: "body,required",
+			expected: &BindTag{
+				Type:     "body",
+				Required: true,
+			},
+		},
+		{
+			name:  "body with options",
+			input: "body,whole,allowunknown,maxbytes=4096",
+			expected: &BindTag{
+				Type:         "body",
+				Whole:        true,
+				AllowUnknown: true,
+				MaxBytes:     4096,
+			},
+		},
+		{
+			name:     "body invalid maxbytes",
+			input:    "body,maxbytes=lots",
+			hasError: true,
+		},
+		{
+			name:     "body option on query",
+			input:    "query,whole",
+			hasError: true,
+		},
+		{
+			name:  "form required",
+			input: "form,required",
+			expected: &BindTag{
+				Type:     "form",
+				Required: true,
+			},
+		},
+		{
+			name:  "file with limits",
+			input: "file,maxbytes=1024,accept=image/png|image/*",
+			expected: &BindTag{
+				Type:     "file",
+				MaxBytes: 1024,
+				Accept:   []string{"image/png", "image/*"},
+			},
+		},
+		{
+			name:     "file invalid accept",
+			input:    "file,accept=png",
+			hasError: true,
+		},
+		{
+			name:  "cookie required",
+			input: "cookie,required",
+			expected: &BindTag{
+				Type:     "cookie",
+				Required: true,
+			},
+		},
+		{
+			name:  "query with name",
+			input: "query=user_id,required",
+			expected: &BindTag{
+				Type:     "query",
+				Name:     "user_id",
+				Required: true,
+			},
+		},
+		{
+			name:  "header with name and spaces",
+			input: " header = X-Request-Id ",
+			expected: &BindTag{
+				Type: "header",
+				Name: "X-Request-Id",
+			},
+		},
+		{
+			name:     "empty name",
+			input:    "query=",
+			hasError: true,
+		},
+		{
+			name:     "invalid bind type with name",
+			input:    "qurey=id",
+			hasError: true,
+		},
+		{
+			name:     "whole body with name",
+			input:    "body=payload,whole",
+			hasError: true,
+		},
+		{
+			name:  "query not exploded",
+			input: "query=ids,explode=false",
+			expected: &BindTag{
+				Type:           "query",
+				Name:           "ids",
+				CommaSeparated: true,
+			},
+		},
+		{
+			name:     "invalid explode value",
+			input:    "query,explode=no",
+			hasError: true,
+		},
+		{
+			name:     "explode on body",
+			input:    "body,explode=false",
+			hasError: true,
+		},
+		{
+			name:  "time layout",
+			input: "query=since,layout=unixmilli",
+			expected: &BindTag{
+				Type:   "query",
+				Name:   "since",
+				Layout: "unixmilli",
+			},
+		},
+		{
+			name:     "empty layout",
+			input:    "query,layout=",
+			hasError: true,
+		},
+		{
+			name:     "layout on body",
+			input:    "body,layout=date",
+			hasError: true,
+		},
+		{
+			name:  "default value",
+			input: "query=limit,default=20",
+			expected: &BindTag{
+				Type:    "query",
+				Name:    "limit",
+				Default: &[]string{"20"}[0],
+			},
+		},
+		{
+			name:     "empty default",
+			input:    "query,default=",
+			hasError: true,
+		},
+		{
+			name:     "default on required",
+			input:    "query,required,default=20",
+			hasError: true,
+		},
+		{
+			name:     "default on body",
+			input:    "body,default=x",
+			hasError: true,
+		},
+		{
+			name:     "accept on form",
+			input:    "form,accept=image/png",
+			hasError: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -283,6 +527,9 @@ func TestParseBindTag(t *testing.T) {
 }
 
 func TestParseValidateTag(t *testing.T) {
+	validators := map[string]Validator{
+		"sku": {Name: "sku", Func: "sku.Validate", ImportPath: "example.com/app/sku", ParamType: "string"},
+	}
 	tests := []struct {
 		name     string
 		input    string
@@ -293,30 +540,97 @@ func TestParseValidateTag(t *testing.T) {
 			name:  "min only",
 			input: "min=18",
 			expected: &ValidateTag{
-				Min: &[]int{18}[0],
+				Min: intBound(18),
 			},
 		},
 		{
 			name:  "max only",
 			input: "max=65",
 			expected: &ValidateTag{
-				Max: &[]int{65}[0],
+				Max: intBound(65),
 			},
 		},
 		{
 			name:  "min and max",
 			input: "min=10,max=20",
 			expected: &ValidateTag{
-				Min: &[]int{10}[0],
-				Max: &[]int{20}[0],
+				Min: intBound(10),
+				Max: intBound(20),
 			},
 		},
 		{
 			name:  "max and min",
 			input: "max=30,min=5",
 			expected: &ValidateTag{
-				Min: &[]int{5}[0],
-				Max: &[]int{30}[0],
+				Min: intBound(5),
+				Max: intBound(30),
+			},
+		},
+		{
+			name:  "exclusive bounds",
+			input: "gt=0.5,lt=1e3",
+			expected: &ValidateTag{
+				Gt: &Bound{Kind: FloatBound, Float: 0.5, Raw: "0.5"},
+				Lt: &Bound{Kind: FloatBound, Float: 1000, Raw: "1e3"},
+			},
+		},
+		{
+			name:  "inclusive aliases",
+			input: "gte=-5,lte=18446744073709551615",
+			expected: &ValidateTag{
+				Min: &Bound{Kind: IntBound, Int: -5, Raw: "-5"},
+				Max: &Bound{Kind: UintBound, Uint: 18446744073709551615, Raw: "18446744073709551615"},
+			},
+		},
+		{
+			name:  "duration bounds",
+			input: "min=1m30s,max=24h",
+			expected: &ValidateTag{
+				Min: &Bound{Kind: DurationBound, Duration: 90 * time.Second, Raw: "1m30s"},
+				Max: &Bound{Kind: DurationBound, Duration: 24 * time.Hour, Raw: "24h"},
+			},
+		},
+		{
+			name:  "time bounds",
+			input: "gt=2024-01-01,lt=2024-06-01T12:00:00Z",
+			expected: &ValidateTag{
+				Gt: &Bound{Kind: TimeBound, Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), Raw: "2024-01-01"},
+				Lt: &Bound{Kind: TimeBound, Time: time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC), Raw: "2024-06-01T12:00:00Z"},
+			},
+		},
+		{
+			name:     "duplicate bound",
+			input:    "min=1,gte=2",
+			hasError: true,
+		},
+		{
+			name:     "infinite bound",
+			input:    "max=inf",
+			hasError: true,
+		},
+		{
+			name:  "string rules",
+			input: "minlen=3,maxlen=20,pattern=^[a-z]+$",
+			expected: &ValidateTag{
+				MinLen:  &[]int{3}[0],
+				MaxLen:  &[]int{20}[0],
+				Pattern: "^[a-z]+$",
+			},
+		},
+		{
+			name:  "len and oneof",
+			input: "len=2,oneof=asc|desc",
+			expected: &ValidateTag{
+				Len:   &[]int{2}[0],
+				OneOf: []string{"asc", "desc"},
+			},
+		},
+		{
+			name:  "escaped commas and bars",
+			input: `pattern=^[a-z]{2\,8}(\|x)?$,oneof=a\,b|c\|d|e`,
+			expected: &ValidateTag{
+				Pattern: `^[a-z]{2,8}(\|x)?$`,
+				OneOf:   []string{"a,b", "c|d", "e"},
 			},
 		},
 		{
@@ -324,6 +638,81 @@ func TestParseValidateTag(t *testing.T) {
 			input:    "min=abc",
 			hasError: true,
 		},
+		{
+			name:  "format",
+			input: "format=email,maxlen=254",
+			expected: &ValidateTag{
+				MaxLen: &[]int{254}[0],
+				Format: "email",
+			},
+		},
+		{
+			name:     "empty format",
+			input:    "format=",
+			hasError: true,
+		},
+		{
+			name:     "negative maxlen",
+			input:    "maxlen=-1",
+			hasError: true,
+		},
+		{
+			name:     "empty pattern",
+			input:    "pattern=",
+			hasError: true,
+		},
+		{
+			name:     "empty oneof",
+			input:    "oneof=",
+			hasError: true,
+		},
+		{
+			name:  "field comparisons",
+			input: "gtfield=From,nefield=Old",
+			expected: &ValidateTag{
+				GtField: "From",
+				NeField: "Old",
+			},
+		},
+		{
+			name:  "presence rules",
+			input: "required_with=A|B,required_without=C,excluded_with=D",
+			expected: &ValidateTag{
+				RequiredWith:    []string{"A", "B"},
+				RequiredWithout: []string{"C"},
+				ExcludedWith:    []string{"D"},
+			},
+		},
+		{
+			name:  "required_if",
+			input: "required_if=Mode:advanced|Level:2",
+			expected: &ValidateTag{
+				RequiredIf: []Condition{{Field: "Mode", Value: "advanced"}, {Field: "Level", Value: "2"}},
+			},
+		},
+		{
+			name:     "invalid field name",
+			input:    "eqfield=new-password",
+			hasError: true,
+		},
+		{
+			name:     "empty required_with field",
+			input:    "required_with=A|",
+			hasError: true,
+		},
+		{
+			name:     "required_if without value",
+			input:    "required_if=Mode",
+			hasError: true,
+		},
+		{
+			name:  "custom validator",
+			input: "sku,maxlen=12",
+			expected: &ValidateTag{
+				MaxLen:     &[]int{12}[0],
+				Validators: []Validator{validators["sku"]},
+			},
+		},
 		{
 			name:     "unsupported rule",
 			input:    "required",
@@ -338,7 +727,7 @@ func TestParseValidateTag(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := parseValidateTag(tt.input)
+			result, err := parseValidateTag(tt.input, validators)
 
 			if tt.hasError {
 				if err == nil {
@@ -372,6 +761,7 @@ func TestProcessField(t *testing.T) {
 		name     string
 		field    *ast.Field
 		expected TagInfo
+		errors   []string
 		hasTag   bool
 	}{
 		{
@@ -393,7 +783,7 @@ func TestProcessField(t *testing.T) {
 			expected: TagInfo{
 				FieldName: "Age",
 				FieldType: "int",
-				Validate:  &ValidateTag{Min: &[]int{18}[0]},
+				Validate:  &ValidateTag{Min: intBound(18)},
 			},
 			hasTag: true,
 		},
@@ -407,10 +797,52 @@ func TestProcessField(t *testing.T) {
 					Type:     "path",
 					Required: true,
 				},
-				Validate: &ValidateTag{Max: &[]int{100}[0]},
+				Validate: &ValidateTag{Max: intBound(100)},
+			},
+			hasTag: true,
+		},
+		{
+			name:  "field with body tag and json name",
+			field: createField("Name", `json:"name,omitempty" bind:"body"`),
+			expected: TagInfo{
+				FieldName: "Name",
+				JSONName:  "name",
+				Bind: &BindTag{
+					Type: "body",
+				},
+			},
+			hasTag: true,
+		},
+		{
+			name:  "field with spaces in tag values",
+			field: createField("Sort", `json:"sort,  omitempty"   bind:"query,default=created at" validate:"oneof=created at|name"`),
+			expected: TagInfo{
+				FieldName: "Sort",
+				JSONName:  "sort",
+				Bind:      &BindTag{Type: "query", Default: &[]string{"created at"}[0]},
+				Validate:  &ValidateTag{OneOf: []string{"created at", "name"}},
 			},
 			hasTag: true,
 		},
+		{
+			name: "field with interpreted string tag",
+			field: &ast.Field{
+				Names: []*ast.Ident{{Name: "Q"}},
+				Tag:   &ast.BasicLit{Kind: token.STRING, Value: `"bind:\"query,default=say \\\"hi\\\"\""`},
+			},
+			expected: TagInfo{
+				FieldName: "Q",
+				Bind:      &BindTag{Type: "query", Default: &[]string{`say "hi"`}[0]},
+			},
+			hasTag: true,
+		},
+		{
+			name:     "field with malformed tag",
+			field:    createField("Name", `bind:query`),
+			expected: TagInfo{},
+			errors:   []string{`malformed struct tag at "bind:query", want key:"value" pairs separated by spaces`},
+			hasTag:   false,
+		},
 		{
 			name:     "field with no tag",
 			field:    &ast.Field{Names: []*ast.Ident{{Name: "Name"}}},
@@ -421,19 +853,36 @@ func TestProcessField(t *testing.T) {
 			name:     "field with invalid bind tag",
 			field:    createField("Name", `bind:"invalid"`),
 			expected: TagInfo{},
+			errors:   []string{`invalid bind type "invalid"`},
 			hasTag:   false,
 		},
 		{
 			name:     "field with invalid validate tag",
 			field:    createField("Name", `validate:"invalid"`),
 			expected: TagInfo{},
+			errors:   []string{`unsupported validation rule "invalid"`},
+			hasTag:   false,
+		},
+		{
+			name:     "field with misspelt tags",
+			field:    createField("Name", `bind:"qurey" validate:"mn=3"`),
+			expected: TagInfo{},
+			errors:   []string{`invalid bind type "qurey", did you mean "query"?`, `unsupported validation rule "mn=3", did you mean "min"?`},
 			hasTag:   false,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, ok := processField(tt.field)
+			result, ok, errs := processField(tt.field, nil)
+
+			var errors []string
+			for _, e := range errs {
+				errors = append(errors, e.err.Error())
+			}
+			if !reflect.DeepEqual(errors, tt.errors) {
+				t.Errorf("processField() errors = %q, want %q", errors, tt.errors)
+			}
 
 			if ok != tt.hasTag {
 				t.Errorf("processField() ok = %v, want %v", ok, tt.hasTag)
@@ -448,6 +897,10 @@ func TestProcessField(t *testing.T) {
 				t.Errorf("FieldName = %v, want %v", result.FieldName, tt.expected.FieldName)
 			}
 
+			if result.JSONName != tt.expected.JSONName {
+				t.Errorf("JSONName = %v, want %v", result.JSONName, tt.expected.JSONName)
+			}
+
 			if !reflect.DeepEqual(result.Bind, tt.expected.Bind) {
 				t.Errorf("Bind = %v, want %v", result.Bind, tt.expected.Bind)
 			}
@@ -458,3 +911,8 @@ func TestProcessField(t *testing.T) {
 		})
 	}
 }
+
+// intBound returns the bound parsed from an integer bound value
+func intBound(n int64) *Bound {
+	return &Bound{Kind: IntBound, Int: n, Raw: strconv.FormatInt(n, 10)}
+}
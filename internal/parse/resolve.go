@@ -0,0 +1,280 @@
+package parse
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// typeResolver resolves the field types of a package from the result of type-checking it: their
+// type as written in the generated code, the packages they come from, their underlying types and
+// whether they implement encoding.TextUnmarshaler. pkg and info are nil when the package couldn't
+// be type-checked, and field types are then taken as written.
+type typeResolver struct {
+	pkg  *types.Package
+	info *types.Info
+}
+
+// newTypeResolver type-checks the package made of files, with the packages it imports loaded from
+// the export data the go command builds for them from dir. Type errors are ignored, as the package
+// commonly calls generated functions that don't exist yet: fields of types that can't be resolved
+// are taken as written. Without files, there is nothing to type-check.
+func newTypeResolver(fset *token.FileSet, dir string, files []*ast.File) (*typeResolver, error) {
+	if len(files) == 0 {
+		return &typeResolver{}, nil
+	}
+	imp, err := exportImporter(fset, dir, files)
+	if err != nil {
+		return nil, err
+	}
+	conf := types.Config{Importer: imp, Error: func(error) {}}
+	info := &types.Info{Types: make(map[ast.Expr]types.TypeAndValue)}
+	pkg, _ := conf.Check(files[0].Name.Name, fset, files, info)
+	return &typeResolver{pkg: pkg, info: info}, nil
+}
+
+// exportImporter returns an importer reading the export data of the packages imported by files and
+// their dependencies, which go list builds, or finds in the build cache, from dir so that import
+// paths resolve in the module of the parsed package.
+func exportImporter(fset *token.FileSet, dir string, files []*ast.File) (types.Importer, error) {
+	var paths []string
+	for _, file := range files {
+		for _, spec := range file.Imports {
+			path, err := strconv.Unquote(spec.Path.Value)
+			if err == nil && path != "C" && !slices.Contains(paths, path) {
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	exports := make(map[string]string)
+	if len(paths) > 0 {
+		cmd := exec.Command("go", append([]string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}", "--"}, paths...)...)
+		cmd.Dir = dir
+		var stderr bytes.Buffer
+		cmd.Stderr = &stderr
+		out, err := cmd.Output()
+		if err != nil {
+			return nil, fmt.Errorf("listing imported packages: %w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if path, export, ok := strings.Cut(line, "\t"); ok && export != "" {
+				exports[path] = export
+			}
+		}
+	}
+
+	return importer.ForCompiler(fset, "gc", func(path string) (io.ReadCloser, error) {
+		export, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(export)
+	}), nil
+}
+
+// textUnmarshaler is the encoding.TextUnmarshaler interface
+var textUnmarshaler = types.NewInterfaceType([]*types.Func{
+	types.NewFunc(token.NoPos, nil, "UnmarshalText", types.NewSignatureType(nil, nil, nil,
+		types.NewTuple(types.NewParam(token.NoPos, nil, "text", types.NewSlice(types.Typ[types.Byte]))),
+		types.NewTuple(types.NewParam(token.NoPos, nil, "", types.Universe.Lookup("error").Type())),
+		false)),
+}, nil).Complete()
+
+// resolveTypes sets the types of tags from the type of their field, found in fields. FieldType is
+// qualified by the package names of the types of other packages, which TypeImports lists, and
+// TextUnmarshaler is set on bound tags whose values implement encoding.TextUnmarshaler. Fields whose
+// type couldn't be resolved keep the type as written, qualified with the imports of file.
+func (r *typeResolver) resolveTypes(file *ast.File, tags []TagInfo, fields []structField) {
+	exprs := make(map[string]ast.Expr, len(fields))
+	for _, field := range fields {
+		exprs[field.name] = field.expr
+	}
+	for i := range tags {
+		tag := &tags[i]
+		t := r.typeOf(exprs[tag.FieldName])
+		if t == nil {
+			tag.TypeImports = typeImports(file, tag.FieldType)
+			tag.ElemType = elemExpr(tag.FieldType)
+			tag.UnderlyingType = tag.FieldType
+			continue
+		}
+		tag.FieldType = r.typeString(t)
+		tag.TypeImports = r.typeImports(t)
+		elem := elemType(t)
+		tag.ElemType = r.typeString(elem)
+		tag.UnderlyingType = r.underlying(t)
+		if tag.Bind != nil && tag.Bind.Type != "body" && tag.Bind.Type != "file" {
+			tag.TextUnmarshaler = types.Implements(types.NewPointer(elem), textUnmarshaler)
+		}
+	}
+}
+
+// typeOf returns the type of a field type expression, nil if it isn't known or isn't valid
+func (r *typeResolver) typeOf(expr ast.Expr) types.Type {
+	if r.info == nil || expr == nil {
+		return nil
+	}
+	t := r.info.TypeOf(expr)
+	if t == nil || strings.Contains(types.TypeString(t, nil), "invalid type") {
+		return nil
+	}
+	return t
+}
+
+// typeString returns t as written in the package, types of other packages being qualified by their
+// package name
+func (r *typeResolver) typeString(t types.Type) string {
+	return types.TypeString(t, func(pkg *types.Package) string {
+		if pkg == r.pkg {
+			return ""
+		}
+		return pkg.Name()
+	})
+}
+
+// typeImports returns the import paths of the other packages whose types t refers to
+func (r *typeResolver) typeImports(t types.Type) []string {
+	var imports []string
+	var walk func(t types.Type)
+	walk = func(t types.Type) {
+		switch t := t.(type) {
+		case *types.Alias:
+			if obj := t.Obj(); obj.Pkg() != nil && obj.Pkg() != r.pkg && !slices.Contains(imports, obj.Pkg().Path()) {
+				imports = append(imports, obj.Pkg().Path())
+			}
+		case *types.Named:
+			if obj := t.Obj(); obj.Pkg() != nil && obj.Pkg() != r.pkg && !slices.Contains(imports, obj.Pkg().Path()) {
+				imports = append(imports, obj.Pkg().Path())
+			}
+			for arg := range t.TypeArgs().Types() {
+				walk(arg)
+			}
+		case *types.Pointer:
+			walk(t.Elem())
+		case *types.Slice:
+			walk(t.Elem())
+		case *types.Array:
+			walk(t.Elem())
+		case *types.Map:
+			walk(t.Key())
+			walk(t.Elem())
+		}
+	}
+	walk(t)
+	return imports
+}
+
+// elemType returns the type of the values bound to a field of type t: the type it points to, the
+// item type of slices other than byte slices, or t itself
+func elemType(t types.Type) types.Type {
+	switch u := t.Underlying().(type) {
+	case *types.Pointer:
+		return u.Elem()
+	case *types.Slice:
+		if !isBytes(u) {
+			return u.Elem()
+		}
+	}
+	return t
+}
+
+// underlying returns t as a type expression with its named types replaced by their underlying
+// types, e.g. []int for a []UserID where UserID is an int. time.Time and time.Duration keep their
+// names, as do the named types whose underlying type isn't a basic type, pointer or slice.
+func (r *typeResolver) underlying(t types.Type) string {
+	t = types.Unalias(t)
+	if named, ok := t.(*types.Named); ok {
+		if obj := named.Obj(); obj.Pkg() != nil && obj.Pkg().Path() == "time" && (obj.Name() == "Time" || obj.Name() == "Duration") {
+			return "time." + obj.Name()
+		}
+	}
+	switch u := t.Underlying().(type) {
+	case *types.Basic:
+		return u.Name()
+	case *types.Pointer:
+		return "*" + r.underlying(u.Elem())
+	case *types.Slice:
+		if isBytes(u) {
+			return "[]byte"
+		}
+		return "[]" + r.underlying(u.Elem())
+	}
+	return r.typeString(t)
+}
+
+// isBytes reports whether s is a slice of bytes
+func isBytes(s *types.Slice) bool {
+	basic, ok := s.Elem().Underlying().(*types.Basic)
+	return ok && basic.Kind() == types.Byte
+}
+
+// resolveKeys sets the underlying type of the keys of the map fields whose type could be resolved,
+// e.g. string for a map[Status]Item where Status is a string
+func (r *typeResolver) resolveKeys(fields []structField) {
+	for i, field := range fields {
+		if t := r.typeOf(field.expr); t != nil {
+			if m, ok := t.Underlying().(*types.Map); ok {
+				fields[i].keyType = r.underlying(m.Key())
+			}
+		}
+	}
+}
+
+// fieldTypes maps the names of fields to their type, as written in the package, and to their
+// underlying type
+func (r *typeResolver) fieldTypes(fields []structField) (map[string]string, map[string]string) {
+	fieldTypes := make(map[string]string, len(fields))
+	underlying := make(map[string]string, len(fields))
+	for _, field := range fields {
+		if t := r.typeOf(field.expr); t != nil {
+			fieldTypes[field.name], underlying[field.name] = r.typeString(t), r.underlying(t)
+		} else {
+			fieldTypes[field.name] = types.ExprString(field.expr)
+			underlying[field.name] = fieldTypes[field.name]
+		}
+	}
+	return fieldTypes, underlying
+}
+
+// elemExpr returns the type of the values bound to a field from its type expression, for fields
+// that weren't type-checked
+func elemExpr(typ string) string {
+	if typ == "[]byte" {
+		return typ
+	}
+	return strings.TrimPrefix(strings.TrimPrefix(typ, "[]"), "*")
+}
+
+// typeImports returns the import paths of the packages qualifying the types in the type expression
+// typ, as imported by file
+func typeImports(file *ast.File, typ string) []string {
+	expr, err := parser.ParseExpr(typ)
+	if err != nil {
+		return nil
+	}
+	var imports []string
+	ast.Inspect(expr, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		if pkg, ok := sel.X.(*ast.Ident); ok {
+			if importPath, ok := fileImport(file, pkg.Name); ok && !slices.Contains(imports, importPath) {
+				imports = append(imports, importPath)
+			}
+		}
+		return false
+	})
+	return imports
+}
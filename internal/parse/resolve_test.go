@@ -0,0 +1,159 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePackageTextUnmarshalers(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"ids/ids.go": `package ids
+
+type OrderID string
+
+func (id *OrderID) UnmarshalText(b []byte) error { return nil }
+
+type Plain string
+`,
+		"ids.go": `package api
+
+type Currency string
+
+func (c *Currency) UnmarshalText(text []byte) error { return nil }
+
+type Level int
+
+func (l Level) UnmarshalText(text string) error { return nil }
+`,
+		"request.go": `package api
+
+import (
+	"net/netip"
+	"time"
+
+	"example.com/app/ids"
+)
+
+type Request struct {
+	Order    ids.OrderID    ` + "`bind:\"path=order\"`" + `
+	Plain    ids.Plain      ` + "`bind:\"query=plain\"`" + `
+	Currency *Currency      ` + "`bind:\"query=currency\"`" + `
+	Level    Level          ` + "`bind:\"query=level\"`" + `
+	Addrs    []netip.Addr   ` + "`bind:\"header=X-Addr\"`" + `
+	Since    time.Time      ` + "`bind:\"body\" json:\"since\"`" + `
+	Count    int            ` + "`bind:\"query=count\"`" + `
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	if len(structs) != 1 {
+		t.Fatalf("ParsePackage() = %+v, want the Request struct only", structs)
+	}
+
+	type field struct {
+		text    bool
+		imports []string
+	}
+	expected := map[string]field{
+		"Order":    {true, []string{"example.com/app/ids"}},
+		"Plain":    {false, []string{"example.com/app/ids"}},
+		"Currency": {true, nil},
+		"Level":    {false, nil},
+		"Addrs":    {true, []string{"net/netip"}},
+		"Since":    {false, []string{"time"}},
+		"Count":    {false, nil},
+	}
+	for _, tag := range structs[0].Tags {
+		got := field{tag.TextUnmarshaler, tag.TypeImports}
+		if !reflect.DeepEqual(got, expected[tag.FieldName]) {
+			t.Errorf("%s: TextUnmarshaler, TypeImports = %v, want %v", tag.FieldName, got, expected[tag.FieldName])
+		}
+	}
+}
+
+func TestParsePackageUnderlyingTypes(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"request.go": `package api
+
+import (
+	"net"
+	"time"
+)
+
+type UserID int64
+
+type Status string
+
+type Tag = string
+
+type Timeout time.Duration
+
+type Request struct {
+	User    UserID        ` + "`bind:\"path=user\"`" + `
+	Manager *UserID       ` + "`bind:\"query=manager\"`" + `
+	Friends []UserID      ` + "`bind:\"query=friend\"`" + `
+	Status  Status        ` + "`bind:\"query=status\"`" + `
+	Tags    []Tag         ` + "`bind:\"query=tag\"`" + `
+	Gateway net.IP        ` + "`bind:\"header=X-Gateway\"`" + `
+	Wait    time.Duration ` + "`bind:\"query=wait\"`" + `
+	Timeout Timeout       ` + "`bind:\"query=timeout\"`" + `
+	Since   *time.Time    ` + "`bind:\"query=since\"`" + `
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	if len(structs) != 1 {
+		t.Fatalf("ParsePackage() = %+v, want the Request struct only", structs)
+	}
+
+	type types struct {
+		field, elem, underlying string
+	}
+	expected := map[string]types{
+		"User":    {"UserID", "UserID", "int64"},
+		"Manager": {"*UserID", "UserID", "*int64"},
+		"Friends": {"[]UserID", "UserID", "[]int64"},
+		"Status":  {"Status", "Status", "string"},
+		"Tags":    {"[]Tag", "Tag", "[]string"},
+		"Gateway": {"net.IP", "net.IP", "[]byte"},
+		"Wait":    {"time.Duration", "time.Duration", "time.Duration"},
+		"Timeout": {"Timeout", "Timeout", "int64"},
+		"Since":   {"*time.Time", "time.Time", "*time.Time"},
+	}
+	for _, tag := range structs[0].Tags {
+		got := types{tag.FieldType, tag.ElemType, tag.UnderlyingType}
+		if got != expected[tag.FieldName] {
+			t.Errorf("%s: FieldType, ElemType, UnderlyingType = %v, want %v", tag.FieldName, got, expected[tag.FieldName])
+		}
+	}
+	if got := structs[0].UnderlyingTypes["Friends"]; got != "[]int64" {
+		t.Errorf("UnderlyingTypes[Friends] = %q, want []int64", got)
+	}
+}
+
+func TestParsePackageEmptyDirectory(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+	})
+
+	structs, pkgName, diags, err := ParsePackage(dir)
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	if len(structs) != 0 || pkgName != "" || len(diags) != 0 {
+		t.Errorf("ParsePackage() = %+v, %q, %v, want nothing", structs, pkgName, diags)
+	}
+	if _, err := newTypeResolver(nil, dir, nil); err != nil {
+		t.Errorf("newTypeResolver() error = %v", err)
+	}
+}
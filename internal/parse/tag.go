@@ -0,0 +1,104 @@
+package parse
+
+import (
+	"fmt"
+	"go/ast"
+	"strconv"
+	"strings"
+)
+
+// fieldTag returns the tag of a struct field, unquoted from its raw or interpreted string literal
+func fieldTag(field *ast.Field) (string, error) {
+	if field.Tag == nil {
+		return "", nil
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return "", fmt.Errorf("malformed struct tag %s", field.Tag.Value)
+	}
+	return tag, nil
+}
+
+// lookupTag returns the value of key in a struct tag, following reflect.StructTag.Lookup: the
+// tag is a list of key:"value" pairs separated by spaces, whose values are quoted Go strings, so
+// they can hold spaces and escaped quotes. Unlike Lookup, tags that don't follow this syntax are
+// reported, rather than ignored from where they go wrong.
+func lookupTag(tag, key string) (string, bool, error) {
+	for tag != "" {
+		tag = strings.TrimLeft(tag, " ")
+		if tag == "" {
+			break
+		}
+
+		// A key is a non-empty run of non-space, non-control characters other than : and "
+		i := 0
+		for i < len(tag) && tag[i] > ' ' && tag[i] != ':' && tag[i] != '"' && tag[i] != 0x7f {
+			i++
+		}
+		if i == 0 || i+1 >= len(tag) || tag[i] != ':' || tag[i+1] != '"' {
+			return "", false, fmt.Errorf("malformed struct tag at %q, want key:\"value\" pairs separated by spaces", tag)
+		}
+		name := tag[:i]
+		tag = tag[i+1:]
+
+		// The value runs to the next unescaped quote
+		i = 1
+		for i < len(tag) && tag[i] != '"' {
+			if tag[i] == '\\' {
+				i++
+			}
+			i++
+		}
+		if i >= len(tag) {
+			return "", false, fmt.Errorf("malformed struct tag: unterminated value of %s", name)
+		}
+		quoted := tag[:i+1]
+		tag = tag[i+1:]
+
+		if name == key {
+			value, err := strconv.Unquote(quoted)
+			if err != nil {
+				return "", false, fmt.Errorf("malformed struct tag: invalid value of %s %s", name, quoted)
+			}
+			return value, true, nil
+		}
+	}
+	return "", false, nil
+}
+
+// splitEscaped splits s around the separators sep that aren't escaped by a backslash, keeping the
+// escapes in the parts
+func splitEscaped(s string, sep byte) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case sep:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// Unescape returns a tag argument with its escaped commas and | unescaped. \, and \| stand for a
+// literal comma and |, which otherwise separate the rules of a tag and the items of a list, and
+// other backslashes are kept as they are.
+func Unescape(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+	return strings.NewReplacer(`\,`, ",", `\|`, "|").Replace(s)
+}
+
+// SplitList splits a tag argument listing items separated by |, such as oneof=a|b|c, into its
+// unescaped items
+func SplitList(s string) []string {
+	items := splitEscaped(s, '|')
+	for i, item := range items {
+		items[i] = Unescape(item)
+	}
+	return items
+}
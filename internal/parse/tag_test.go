@@ -0,0 +1,68 @@
+package parse
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookupTag(t *testing.T) {
+	tests := []struct {
+		name     string
+		tag      string
+		key      string
+		expected string
+		found    bool
+		hasError bool
+	}{
+		{"simple", `bind:"query"`, "bind", "query", true, false},
+		{"second key", `json:"name" bind:"query=name"`, "bind", "query=name", true, false},
+		{"extra spaces", `json:"name"    bind:"query"  `, "bind", "query", true, false},
+		{"space in value", `validate:"pattern=^a b$"`, "validate", "pattern=^a b$", true, false},
+		{"escaped quote", `bind:"query,default=\"x\""`, "bind", `query,default="x"`, true, false},
+		{"missing key", `json:"name"`, "bind", "", false, false},
+		{"key prefix", `xbind:"query"`, "bind", "", false, false},
+		{"empty value", `bind:""`, "bind", "", true, false},
+		{"unquoted value", `bind:query`, "bind", "", false, true},
+		{"unterminated value", `bind:"query`, "bind", "", false, true},
+		{"space before colon", `bind :"query"`, "bind", "", false, true},
+		{"invalid escape", `bind:"a\,b"`, "bind", "", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, found, err := lookupTag(tt.tag, tt.key)
+			if tt.hasError {
+				if err == nil {
+					t.Errorf("lookupTag() expected error, got %q", value)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("lookupTag() error = %v", err)
+			}
+			if value != tt.expected || found != tt.found {
+				t.Errorf("lookupTag() = %q, %v, want %q, %v", value, found, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestSplitList(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected []string
+	}{
+		{"a|b|c", []string{"a", "b", "c"}},
+		{"a", []string{"a"}},
+		{`a\|b|c`, []string{"a|b", "c"}},
+		{`a\,b|c`, []string{"a,b", "c"}},
+		{`\d|x`, []string{`\d`, "x"}},
+		{"a||b", []string{"a", "", "b"}},
+	}
+
+	for _, tt := range tests {
+		if got := SplitList(tt.input); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("SplitList(%q) = %q, want %q", tt.input, got, tt.expected)
+		}
+	}
+}
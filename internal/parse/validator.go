@@ -0,0 +1,198 @@
+package parse
+
+import (
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// validatorDirective declares a custom validator, as //wrangler:validator name Func for a function of
+// the package, name pkg.Func for a function of a package imported by the file, or name path/to/pkg.Func
+const validatorDirective = "//wrangler:validator"
+
+// Validator is a custom validation rule declared with a //wrangler:validator directive
+// Name is the rule name used in validate tags, and Func the function checking it, qualified by
+// its package name when it comes from the package with import path ImportPath, which is empty
+// for functions of the parsed package.
+// The function takes a single value of ParamType and returns an error.
+type Validator struct {
+	Name       string
+	Func       string
+	ImportPath string
+	ParamType  string
+}
+
+// builtinRules are the names of the built-in validate rules, which validators can't take, and
+// which misspelt rules are suggested from along with the validators
+var builtinRules = []string{
+	"min", "gte", "max", "lte", "gt", "lt", "minlen", "maxlen", "len", "pattern", "oneof", "format",
+	"eqfield", "nefield", "gtfield", "gtefield", "ltfield", "ltefield",
+	"required_with", "required_without", "required_if", "excluded_with",
+}
+
+// findValidators returns the validators declared by directives in the files of a package, by name.
+// Functions of other packages are resolved from their source, relative to dir.
+func findValidators(fset *token.FileSet, dir string, files []*ast.File) (map[string]Validator, error) {
+	validators := make(map[string]Validator)
+	for _, file := range files {
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				args, ok := strings.CutPrefix(comment.Text, validatorDirective)
+				if !ok || (args != "" && args[0] != ' ' && args[0] != '\t') {
+					continue
+				}
+				pos := fset.Position(comment.Pos())
+				fields := strings.Fields(args)
+				if len(fields) != 2 {
+					return nil, fmt.Errorf("%s: invalid directive %q, want %s name Func or %s name pkg.Func", pos, comment.Text, validatorDirective, validatorDirective)
+				}
+				name := fields[0]
+				if !token.IsIdentifier(name) || slices.Contains(builtinRules, name) {
+					return nil, fmt.Errorf("%s: invalid validator name %q", pos, name)
+				}
+				if _, ok := validators[name]; ok {
+					return nil, fmt.Errorf("%s: validator %s declared twice", pos, name)
+				}
+				validator, err := resolveValidator(fset, dir, files, file, fields[1])
+				if err != nil {
+					return nil, fmt.Errorf("%s: validator %s: %w", pos, name, err)
+				}
+				validator.Name = name
+				validators[name] = validator
+			}
+		}
+	}
+	return validators, nil
+}
+
+// resolveValidator finds the function of a validator directive, declared in the parsed package or
+// in another package, and checks that it's a func(T) error. Other packages are loaded from dir.
+func resolveValidator(fset *token.FileSet, dir string, files []*ast.File, file *ast.File, ref string) (Validator, error) {
+	dot := strings.LastIndex(ref, ".")
+	if dot < 0 {
+		fn := findFunc(files, ref)
+		if fn == nil {
+			return Validator{}, fmt.Errorf("function %s not found in the package", ref)
+		}
+		paramType, err := validatorParam(fn, "")
+		if err != nil {
+			return Validator{}, err
+		}
+		return Validator{Func: ref, ParamType: paramType}, nil
+	}
+
+	pkgRef, funcName := ref[:dot], ref[dot+1:]
+	if pkgRef == "" || !token.IsExported(funcName) {
+		return Validator{}, fmt.Errorf("invalid function %q", ref)
+	}
+	// A package name imported by the file refers to that import, anything else is an import path
+	importPath, ok := fileImport(file, pkgRef)
+	if !ok {
+		importPath = pkgRef
+	}
+	pkg, pkgFiles, err := loadPackage(fset, dir, importPath)
+	if err != nil {
+		return Validator{}, err
+	}
+	fn := findFunc(pkgFiles, funcName)
+	if fn == nil {
+		return Validator{}, fmt.Errorf("function %s not found in %s", funcName, pkg.ImportPath)
+	}
+	paramType, err := validatorParam(fn, pkg.Name)
+	if err != nil {
+		return Validator{}, err
+	}
+	return Validator{Func: pkg.Name + "." + funcName, ImportPath: pkg.ImportPath, ParamType: paramType}, nil
+}
+
+// fileImport returns the import path of the package imported by file as name
+func fileImport(file *ast.File, name string) (string, bool) {
+	for _, spec := range file.Imports {
+		specPath, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			continue
+		}
+		specName := path.Base(specPath)
+		if spec.Name != nil {
+			specName = spec.Name.Name
+		}
+		if specName == name {
+			return specPath, true
+		}
+	}
+	return "", false
+}
+
+// loadPackage finds the package with the given import path like the go command does from dir, and
+// parses its source files.
+func loadPackage(fset *token.FileSet, dir, importPath string) (*build.Package, []*ast.File, error) {
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The go command is run from dir, to resolve import paths in the module of the parsed package
+	ctxt := build.Default
+	ctxt.Dir = absDir
+	pkg, err := ctxt.Import(importPath, absDir, 0)
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []*ast.File
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, filepath.Join(pkg.Dir, name), nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, f)
+	}
+	return pkg, files, nil
+}
+
+// findFunc returns the declaration of the package level function name in files, nil if there's none
+func findFunc(files []*ast.File, name string) *ast.FuncDecl {
+	for _, file := range files {
+		for _, decl := range file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == name {
+				return fn
+			}
+		}
+	}
+	return nil
+}
+
+// validatorParam checks that a validator function is a func(T) error and returns T. Types declared
+// by the function's package are qualified by pkgName, if not empty, as they are written outside it.
+func validatorParam(fn *ast.FuncDecl, pkgName string) (string, error) {
+	params, results := fn.Type.Params.List, fn.Type.Results
+	if fn.Type.TypeParams != nil || len(params) != 1 || len(params[0].Names) > 1 ||
+		results == nil || len(results.List) != 1 || len(results.List[0].Names) > 1 || types.ExprString(results.List[0].Type) != "error" {
+		return "", fmt.Errorf("%s must be a func(T) error", fn.Name.Name)
+	}
+	param := params[0].Type
+	if _, ok := param.(*ast.Ellipsis); ok {
+		return "", fmt.Errorf("%s must be a func(T) error", fn.Name.Name)
+	}
+	if pkgName != "" {
+		ast.Inspect(param, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.SelectorExpr:
+				// Already qualified by another package
+				return false
+			case *ast.Ident:
+				if types.Universe.Lookup(n.Name) == nil {
+					n.Name = pkgName + "." + n.Name
+				}
+			}
+			return true
+		})
+	}
+	return types.ExprString(param), nil
+}
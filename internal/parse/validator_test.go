@@ -0,0 +1,128 @@
+package parse
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeModule writes the files of a module to a temporary directory and returns it
+func writeModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("Failed to create directory for %s: %v", name, err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+	}
+	return dir
+}
+
+func TestParsePackageValidators(t *testing.T) {
+	dir := writeModule(t, map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.24\n",
+		"sku/sku.go": `package sku
+
+type Code string
+
+func Validate(s string) error { return nil }
+
+func ValidateCode(c Code) error { return nil }
+
+func ValidateCodes(codes []*Code) error { return nil }
+`,
+		"api/validators.go": `package api
+
+import "strings"
+
+//wrangler:validator sku example.com/app/sku.Validate
+//wrangler:validator slug validateSlug
+
+func validateSlug(s string) error {
+	_ = strings.ToLower(s)
+	return nil
+}
+`,
+		"api/order.go": `package api
+
+import (
+	catalog "example.com/app/sku"
+)
+
+//wrangler:validator code catalog.ValidateCode
+//wrangler:validator codes catalog.ValidateCodes
+
+type Order struct {
+	SKU    string         ` + "`validate:\"sku,maxlen=12\"`" + `
+	Code   catalog.Code   ` + "`validate:\"code\"`" + `
+	Codes  []*catalog.Code ` + "`validate:\"codes\"`" + `
+	Tenant string         ` + "`validate:\"slug\"`" + `
+}
+`,
+	})
+
+	structs, _, _, err := ParsePackage(filepath.Join(dir, "api"))
+	if err != nil {
+		t.Fatalf("ParsePackage() error = %v", err)
+	}
+	if len(structs) != 1 || len(structs[0].Tags) != 4 {
+		t.Fatalf("ParsePackage() = %+v, want the 4 fields of Order", structs)
+	}
+
+	expected := []Validator{
+		{Name: "sku", Func: "sku.Validate", ImportPath: "example.com/app/sku", ParamType: "string"},
+		{Name: "code", Func: "sku.ValidateCode", ImportPath: "example.com/app/sku", ParamType: "sku.Code"},
+		{Name: "codes", Func: "sku.ValidateCodes", ImportPath: "example.com/app/sku", ParamType: "[]*sku.Code"},
+		{Name: "slug", Func: "validateSlug", ParamType: "string"},
+	}
+	for i, want := range expected {
+		got := structs[0].Tags[i].Validate.Validators
+		if !reflect.DeepEqual(got, []Validator{want}) {
+			t.Errorf("%s validators = %+v, want %+v", structs[0].Tags[i].FieldName, got, want)
+		}
+	}
+}
+
+func TestParsePackageValidatorErrors(t *testing.T) {
+	tests := []struct {
+		name      string
+		directive string
+		wantErr   string
+	}{
+		{"missing function", "//wrangler:validator sku", "invalid directive"},
+		{"builtin name", "//wrangler:validator min validateSKU", `invalid validator name "min"`},
+		{"invalid name", "//wrangler:validator tenant-slug validateSKU", `invalid validator name "tenant-slug"`},
+		{"duplicate", "//wrangler:validator sku validateSKU\n//wrangler:validator sku validateSKU", "validator sku declared twice"},
+		{"unknown function", "//wrangler:validator sku checkSKU", "function checkSKU not found in the package"},
+		{"wrong signature", "//wrangler:validator sku isSKU", "isSKU must be a func(T) error"},
+		{"unexported function", "//wrangler:validator sku strings.toLower", `invalid function "strings.toLower"`},
+		{"unknown package function", "//wrangler:validator sku strings.ValidateSKU", "function ValidateSKU not found in strings"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeModule(t, map[string]string{
+				"go.mod": "module example.com/app\n\ngo 1.24\n",
+				"api.go": `package api
+
+` + tt.directive + `
+
+func validateSKU(s string) error { return nil }
+
+func isSKU(s string) bool { return true }
+`,
+			})
+
+			_, _, _, err := ParsePackage(dir)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ParsePackage() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
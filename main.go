@@ -6,6 +6,8 @@ import (
 	"log"
 	"os"
 	"path/filepath"
+	"runtime/debug"
+	"strconv"
 	"strings"
 
 	"github.com/pangobit/go-wrangler/internal/generator"
@@ -32,6 +34,9 @@ func main() {
 	targetPkg := flag.String("target-pkg", "", "Target package name for single strategy")
 	targetDir := flag.String("target-dir", "", "Target directory for per or single strategy")
 	targetPkgs := flag.String("target-pkgs", "", "Target package names for per strategy (space-separated)")
+	failFast := flag.Bool("fail-fast", false, "Return the first bind or validation failure instead of collecting them all")
+	lenient := flag.Bool("lenient", false, "Skip the fields with malformed tags instead of failing")
+	provenance := flag.Bool("provenance", false, "Record the go-wrangler version, command line and a hash of the source structs in the header of generated files")
 	flag.Parse()
 
 	args := flag.Args()
@@ -40,10 +45,16 @@ func main() {
 	}
 
 	dirs := args
+	opts := generator.Options{FailFast: *failFast}
+	if *provenance {
+		opts.Version = version()
+		opts.Command = commandLine()
+		opts.SourceHash = true
+	}
 
 	switch *strategy {
 	case "same":
-		processSame(dirs)
+		processSame(dirs, *lenient, opts)
 	case "per":
 		if *targetDir == "" || *targetPkgs == "" {
 			log.Fatal("per strategy requires --target-dir and --target-pkgs")
@@ -52,23 +63,57 @@ func main() {
 		if len(pkgList) != len(dirs) {
 			log.Fatal("number of target packages must match number of input directories")
 		}
-		processPer(dirs, pkgList, *targetDir)
+		processPer(dirs, pkgList, *targetDir, *lenient, opts)
 	case "single":
 		if *targetPkg == "" || *targetDir == "" {
 			log.Fatal("single strategy requires --target-pkg and --target-dir")
 		}
-		processSingle(dirs, *targetPkg, *targetDir)
+		processSingle(dirs, *targetPkg, *targetDir, *lenient, opts)
 	default:
 		log.Fatalf("Unknown strategy: %s", *strategy)
 	}
 }
 
-func processSame(dirs []string) {
-	for _, dir := range dirs {
-		structs, pkgName, err := parse.ParsePackage(dir)
-		if err != nil {
-			log.Fatalf("Failed to parse package %s: %v", dir, err)
+// version returns the module version of the running go-wrangler, (devel) when built from a checkout.
+func version() string {
+	if info, ok := debug.ReadBuildInfo(); ok {
+		return info.Main.Version
+	}
+	return "(devel)"
+}
+
+// commandLine returns the command line the tool was run with, quoting the arguments that aren't
+// plain words.
+func commandLine() string {
+	args := []string{filepath.Base(os.Args[0])}
+	for _, arg := range os.Args[1:] {
+		if arg == "" || strings.ContainsAny(arg, " \t\n\"'\\") {
+			arg = strconv.Quote(arg)
 		}
+		args = append(args, arg)
+	}
+	return strings.Join(args, " ")
+}
+
+// parsePackage parses the package in dir, printing the diagnostics of its malformed tags. These
+// stop the generation, unless lenient is set and the fields with malformed tags are skipped.
+func parsePackage(dir string, lenient bool) ([]parse.StructInfo, string) {
+	structs, pkgName, diags, err := parse.ParsePackage(dir)
+	if err != nil {
+		log.Fatalf("Failed to parse package %s: %v", dir, err)
+	}
+	for _, d := range diags {
+		fmt.Fprintln(os.Stderr, d)
+	}
+	if len(diags) > 0 && !lenient {
+		log.Fatalf("Malformed tags in %s, fix them or use --lenient to skip their fields", dir)
+	}
+	return structs, pkgName
+}
+
+func processSame(dirs []string, lenient bool, opts generator.Options) {
+	for _, dir := range dirs {
+		structs, pkgName := parsePackage(dir, lenient)
 
 		if len(structs) == 0 {
 			fmt.Printf("No structs in %s\n", dir)
@@ -83,7 +128,10 @@ func processSame(dirs []string) {
 		outPkg := pkgName
 		filePath := filepath.Join(outDir, pkgName+"_bindings.go")
 
-		code := generator.GeneratePackage(structs, outPkg)
+		code, err := generator.GeneratePackage(structs, outPkg, opts)
+		if err != nil {
+			log.Fatalf("Failed to generate code for %s: %v", dir, err)
+		}
 
 		err = os.WriteFile(filePath, []byte(code), 0644)
 		if err != nil {
@@ -94,12 +142,9 @@ func processSame(dirs []string) {
 	}
 }
 
-func processPer(dirs []string, targetPkgs []string, targetDir string) {
+func processPer(dirs []string, targetPkgs []string, targetDir string, lenient bool, opts generator.Options) {
 	for i, dir := range dirs {
-		structs, _, err := parse.ParsePackage(dir)
-		if err != nil {
-			log.Fatalf("Failed to parse package %s: %v", dir, err)
-		}
+		structs, _ := parsePackage(dir, lenient)
 
 		if len(structs) == 0 {
 			fmt.Printf("No structs in %s\n", dir)
@@ -114,12 +159,15 @@ func processPer(dirs []string, targetPkgs []string, targetDir string) {
 		outDir := filepath.Join(targetDir, outPkg)
 		filePath := filepath.Join(outDir, "generated.go")
 
-		err = os.MkdirAll(outDir, 0755)
+		err := os.MkdirAll(outDir, 0755)
 		if err != nil {
 			log.Fatalf("Failed to create output directory: %v", err)
 		}
 
-		code := generator.GeneratePackage(structs, outPkg)
+		code, err := generator.GeneratePackage(structs, outPkg, opts)
+		if err != nil {
+			log.Fatalf("Failed to generate code for %s: %v", dir, err)
+		}
 
 		err = os.WriteFile(filePath, []byte(code), 0644)
 		if err != nil {
@@ -130,13 +178,10 @@ func processPer(dirs []string, targetPkgs []string, targetDir string) {
 	}
 }
 
-func processSingle(dirs []string, targetPkg, targetDir string) {
+func processSingle(dirs []string, targetPkg, targetDir string, lenient bool, opts generator.Options) {
 	allStructs := []parse.StructInfo{}
 	for _, dir := range dirs {
-		structs, _, err := parse.ParsePackage(dir)
-		if err != nil {
-			log.Fatalf("Failed to parse package %s: %v", dir, err)
-		}
+		structs, _ := parsePackage(dir, lenient)
 
 		if len(structs) == 0 {
 			fmt.Printf("No structs in %s\n", dir)
@@ -161,7 +206,10 @@ func processSingle(dirs []string, targetPkg, targetDir string) {
 	}
 
 	filePath := filepath.Join(targetDir, "generated.go")
-	code := generator.GeneratePackage(allStructs, targetPkg)
+	code, err := generator.GeneratePackage(allStructs, targetPkg, opts)
+	if err != nil {
+		log.Fatalf("Failed to generate code: %v", err)
+	}
 
 	err = os.WriteFile(filePath, []byte(code), 0644)
 	if err != nil {
@@ -169,4 +217,4 @@ func processSingle(dirs []string, targetPkg, targetDir string) {
 	}
 
 	fmt.Printf("Generated code written to %s\n", filePath)
-}
\ No newline at end of file
+}
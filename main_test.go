@@ -5,6 +5,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/pangobit/go-wrangler/internal/generator"
 )
 
 func TestProcessSame(t *testing.T) {
@@ -23,7 +25,7 @@ type TestStruct struct {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
 
-	processSame([]string{tempDir})
+	processSame([]string{tempDir}, false, generator.Options{})
 
 	// Check file created
 	expectedFile := filepath.Join(tempDir, "testpkg_bindings.go")
@@ -38,6 +40,9 @@ type TestStruct struct {
 	}
 	str := string(data)
 
+	if !strings.HasPrefix(str, "// Code generated by go-wrangler. DO NOT EDIT.\n") {
+		t.Errorf("Expected generated file header before the package clause")
+	}
 	if !strings.Contains(str, "package testpkg") {
 		t.Errorf("Expected package testpkg in generated file")
 	}
@@ -63,7 +68,7 @@ type TestStruct struct {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
 
-	processPer([]string{tempDir}, []string{"otarget"}, targetDir)
+	processPer([]string{tempDir}, []string{"otarget"}, targetDir, false, generator.Options{})
 
 	// Check file created
 	expectedFile := filepath.Join(targetDir, "otarget", "generated.go")
@@ -103,7 +108,7 @@ type TestStruct struct {
 		t.Fatalf("Failed to write test file: %v", err)
 	}
 
-	processSingle([]string{tempDir}, "bindings", targetDir)
+	processSingle([]string{tempDir}, "bindings", targetDir, false, generator.Options{})
 
 	// Check file created
 	expectedFile := filepath.Join(targetDir, "generated.go")
@@ -124,4 +129,37 @@ type TestStruct struct {
 	if !strings.Contains(str, "func BindTestStruct") {
 		t.Errorf("Expected BindTestStruct function in generated file")
 	}
-}
\ No newline at end of file
+}
+
+func TestProcessSameLenient(t *testing.T) {
+	tempDir := t.TempDir()
+
+	// Create a test.go file with a misspelt bind tag
+	testFile := filepath.Join(tempDir, "test.go")
+	content := `package testpkg
+
+type TestStruct struct {
+	Name string ` + "`bind:\"query\"`" + `
+	Page int    ` + "`bind:\"qurey\"`" + `
+}
+`
+	err := os.WriteFile(testFile, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Failed to write test file: %v", err)
+	}
+
+	processSame([]string{tempDir}, true, generator.Options{})
+
+	data, err := os.ReadFile(filepath.Join(tempDir, "testpkg_bindings.go"))
+	if err != nil {
+		t.Fatalf("Failed to read generated file: %v", err)
+	}
+	str := string(data)
+
+	if !strings.Contains(str, "s.Name = v") {
+		t.Errorf("Expected Name to be bound in generated file")
+	}
+	if strings.Contains(str, "s.Page") {
+		t.Errorf("Expected Page, with a malformed tag, to be skipped")
+	}
+}
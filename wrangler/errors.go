@@ -0,0 +1,99 @@
+// Package wrangler contains the runtime support used by code generated by go-wrangler
+package wrangler
+
+import "strings"
+
+// FieldError describes a field that failed binding or validation
+// Field is the Go field name, empty for errors about the request as a whole, such as a body
+// that isn't valid JSON.
+// Param and Source are the request parameter name and the bind type (query, header, body, ...)
+// the field is bound from, both empty for fields without a bind tag.
+// Rule is the bind option or validate rule that failed, e.g. required, type, range or min.
+// Message is the human readable error, and Err the underlying error, if any.
+type FieldError struct {
+	Field   string
+	Param   string
+	Source  string
+	Rule    string
+	Message string
+	Err     error
+}
+
+// Error returns the message of the field error
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+// Unwrap returns the underlying error, if any
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is the error returned by generated bind and validate functions, listing every field
+// that failed in the order the fields are declared. Use errors.As with an *Errors target to
+// get all of them, or with a **FieldError target to get the first.
+type Errors []*FieldError
+
+// Error joins the messages of all field errors
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, fe := range e {
+		messages[i] = fe.Message
+	}
+	return strings.Join(messages, "; ")
+}
+
+// Unwrap returns the field errors, for errors.Is and errors.As
+func (e Errors) Unwrap() []error {
+	errs := make([]error, len(e))
+	for i, fe := range e {
+		errs[i] = fe
+	}
+	return errs
+}
+
+// Err returns e as an error, or nil if it is empty, so that an empty list is never returned
+// as a non-nil error.
+func (e Errors) Err() error {
+	if len(e) == 0 {
+		return nil
+	}
+	return e
+}
+
+// Append returns e with the failures of err added, for merging the error of a struct's own
+// Validate or ValidateContext method. The field errors of an Errors or a *FieldError are added
+// as they are, and any other error as a FieldError with the rule validate. A nil err adds nothing.
+func (e Errors) Append(err error) Errors {
+	switch err := err.(type) {
+	case nil:
+		return e
+	case Errors:
+		return append(e, err...)
+	case *FieldError:
+		return append(e, err)
+	default:
+		return append(e, &FieldError{Rule: "validate", Message: err.Error(), Err: err})
+	}
+}
+
+// Nest returns e with the failures of err, the error of the validate function of a nested struct,
+// added under path, the selector of the struct from the outer one, such as Items[3]. Their Field,
+// and their Message when it starts with it, are prefixed with path, as in Items[3].Quantity.
+// Other messages are prefixed with path and a colon.
+func (e Errors) Nest(path string, err error) Errors {
+	for _, fe := range Errors(nil).Append(err) {
+		nested := *fe
+		nested.Field = path
+		if fe.Field != "" {
+			nested.Field = path + "." + fe.Field
+		}
+		if rest, ok := strings.CutPrefix(fe.Message, fe.Field); ok && fe.Field != "" {
+			nested.Message = nested.Field + rest
+		} else {
+			nested.Message = path + ": " + fe.Message
+		}
+		e = append(e, &nested)
+	}
+	return e
+}
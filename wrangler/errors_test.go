@@ -0,0 +1,82 @@
+package wrangler
+
+import (
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestErrors(t *testing.T) {
+	var err error = Errors{
+		{Field: "Limit", Param: "limit", Source: "query", Rule: "type", Message: "limit must be a valid integer"},
+		{Field: "", Source: "body", Rule: "json", Message: "invalid JSON request body: unexpected EOF", Err: io.ErrUnexpectedEOF},
+	}
+
+	if got, want := err.Error(), "limit must be a valid integer; invalid JSON request body: unexpected EOF"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+
+	var errs Errors
+	if !errors.As(err, &errs) || len(errs) != 2 {
+		t.Errorf("errors.As(Errors) = %v, want both field errors", errs)
+	}
+
+	var fe *FieldError
+	if !errors.As(err, &fe) || fe.Field != "Limit" {
+		t.Errorf("errors.As(*FieldError) = %v, want the Limit error", fe)
+	}
+
+	if !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("errors.Is(err, io.ErrUnexpectedEOF) = false, want true")
+	}
+}
+
+func TestErrorsErr(t *testing.T) {
+	var errs Errors
+	if err := errs.Err(); err != nil {
+		t.Errorf("Err() = %v, want nil", err)
+	}
+
+	errs = append(errs, &FieldError{Message: "Name is required"})
+	if err := errs.Err(); err == nil || err.Error() != "Name is required" {
+		t.Errorf("Err() = %v, want Name is required", err)
+	}
+}
+
+func TestErrorsAppend(t *testing.T) {
+	errs := Errors{{Field: "Qty", Rule: "min", Message: "Qty must be at least 1"}}
+
+	if got := errs.Append(nil); len(got) != 1 {
+		t.Errorf("Append(nil) = %v, want the Qty error only", got)
+	}
+
+	got := errs.Append(Errors{{Field: "SKU", Rule: "sku", Message: "unknown SKU"}})
+	got = got.Append(&FieldError{Field: "Total", Message: "Total doesn't match the items"})
+	got = got.Append(io.ErrUnexpectedEOF)
+	if want := "Qty must be at least 1; unknown SKU; Total doesn't match the items; unexpected EOF"; got.Error() != want {
+		t.Errorf("Append() = %q, want %q", got.Error(), want)
+	}
+	if last := got[len(got)-1]; last.Rule != "validate" || last.Err != io.ErrUnexpectedEOF {
+		t.Errorf("Append(io.ErrUnexpectedEOF) added %+v, want a validate rule wrapping the error", last)
+	}
+}
+
+func TestErrorsNest(t *testing.T) {
+	inner := Errors{
+		{Field: "Quantity", Rule: "min", Message: "Quantity must be at least 1"},
+		{Rule: "validate", Message: "the item is out of stock"},
+	}
+
+	got := Errors(nil).Nest("Items[3]", inner)
+	got = got.Nest("Items[4]", nil)
+	got = got.Nest("Address", io.ErrUnexpectedEOF)
+	if want := "Items[3].Quantity must be at least 1; Items[3]: the item is out of stock; Address: unexpected EOF"; got.Error() != want {
+		t.Errorf("Nest() = %q, want %q", got.Error(), want)
+	}
+	if got[0].Field != "Items[3].Quantity" || got[1].Field != "Items[3]" || got[2].Field != "Address" {
+		t.Errorf("Nest() fields = %q, %q, %q, want Items[3].Quantity, Items[3] and Address", got[0].Field, got[1].Field, got[2].Field)
+	}
+	if inner[0].Field != "Quantity" {
+		t.Errorf("Nest() changed the nested error to %+v", inner[0])
+	}
+}
@@ -0,0 +1,99 @@
+package wrangler
+
+import (
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// IsEmail reports whether s is a bare email address, e.g. gopher@example.com,
+// without a display name or angle brackets.
+func IsEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Name == "" && addr.Address == s
+}
+
+// IsURI reports whether s is an absolute URI, i.e. one with a scheme, such as mailto:gopher@example.com.
+func IsURI(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs()
+}
+
+// IsURL reports whether s is an absolute URL with a host, such as https://example.com/callback.
+func IsURL(s string) bool {
+	u, err := url.Parse(s)
+	return err == nil && u.IsAbs() && u.Host != ""
+}
+
+// IsUUID reports whether s is a UUID in its canonical 8-4-4-4-12 hexadecimal form, of any version.
+func IsUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case i == 8 || i == 13 || i == 18 || i == 23:
+			if c != '-' {
+				return false
+			}
+		case '0' <= c && c <= '9', 'a' <= c && c <= 'f', 'A' <= c && c <= 'F':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// IsIPv4 reports whether s is an IPv4 address in dotted decimal form.
+func IsIPv4(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is4()
+}
+
+// IsIPv6 reports whether s is an IPv6 address without a zone.
+func IsIPv6(s string) bool {
+	addr, err := netip.ParseAddr(s)
+	return err == nil && addr.Is6() && addr.Zone() == ""
+}
+
+// IsCIDR reports whether s is an IPv4 or IPv6 prefix in CIDR notation, e.g. 10.0.0.0/8.
+func IsCIDR(s string) bool {
+	_, err := netip.ParsePrefix(s)
+	return err == nil
+}
+
+// IsHostname reports whether s is a hostname as defined by RFC 1123: dot separated labels of
+// letters, digits and hyphens, each at most 63 characters long and not starting or ending with
+// a hyphen, and at most 253 characters in total.
+func IsHostname(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			switch c := label[i]; {
+			case '0' <= c && c <= '9', 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', c == '-':
+			default:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// IsDate reports whether s is an RFC 3339 full date, e.g. 2006-01-02.
+func IsDate(s string) bool {
+	_, err := time.Parse(time.DateOnly, s)
+	return err == nil
+}
+
+// IsDateTime reports whether s is an RFC 3339 date and time, e.g. 2006-01-02T15:04:05Z07:00.
+func IsDateTime(s string) bool {
+	_, err := time.Parse(time.RFC3339, s)
+	return err == nil
+}
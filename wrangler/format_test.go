@@ -0,0 +1,91 @@
+package wrangler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		name    string
+		fn      func(string) bool
+		valid   []string
+		invalid []string
+	}{
+		{
+			name:    "email",
+			fn:      IsEmail,
+			valid:   []string{"gopher@example.com", "first.last+tag@sub.example.org"},
+			invalid: []string{"", "gopher", "gopher@", "Gopher <gopher@example.com>", "<gopher@example.com>", "a@b@c"},
+		},
+		{
+			name:    "uri",
+			fn:      IsURI,
+			valid:   []string{"https://example.com", "mailto:gopher@example.com", "urn:isbn:0451450523"},
+			invalid: []string{"", "example.com", "/relative/path", "://missing-scheme"},
+		},
+		{
+			name:    "url",
+			fn:      IsURL,
+			valid:   []string{"https://example.com/callback?x=1", "http://localhost:8080"},
+			invalid: []string{"", "mailto:gopher@example.com", "example.com/path", "https://"},
+		},
+		{
+			name:    "uuid",
+			fn:      IsUUID,
+			valid:   []string{"123e4567-e89b-12d3-a456-426614174000", "00000000-0000-0000-0000-000000000000", "123E4567-E89B-12D3-A456-426614174000"},
+			invalid: []string{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g", "{123e4567-e89b-12d3-a456-426614174000}"},
+		},
+		{
+			name:    "ipv4",
+			fn:      IsIPv4,
+			valid:   []string{"192.168.0.1", "0.0.0.0"},
+			invalid: []string{"", "256.0.0.1", "192.168.0", "::1", "010.0.0.1"},
+		},
+		{
+			name:    "ipv6",
+			fn:      IsIPv6,
+			valid:   []string{"::1", "2001:db8::ff00:42:8329"},
+			invalid: []string{"", "192.168.0.1", "fe80::1%eth0", "2001:db8:::1"},
+		},
+		{
+			name:    "cidr",
+			fn:      IsCIDR,
+			valid:   []string{"10.0.0.0/8", "2001:db8::/32"},
+			invalid: []string{"", "10.0.0.0", "10.0.0.0/33", "10.0.0.0/x"},
+		},
+		{
+			name:    "hostname",
+			fn:      IsHostname,
+			valid:   []string{"localhost", "api.example.com", "xn--bcher-kva.example", "a-b.c1"},
+			invalid: []string{"", "-api.example.com", "api-.example.com", "api..example.com", "api_v2.example.com", strings.Repeat("a", 64) + ".com"},
+		},
+		{
+			name:    "date",
+			fn:      IsDate,
+			valid:   []string{"2024-02-29", "1999-12-31"},
+			invalid: []string{"", "2023-02-29", "2024-1-2", "2024-01-02T00:00:00Z"},
+		},
+		{
+			name:    "date-time",
+			fn:      IsDateTime,
+			valid:   []string{"2024-01-02T15:04:05Z", "2024-01-02T15:04:05.123+02:00"},
+			invalid: []string{"", "2024-01-02", "2024-01-02 15:04:05Z", "2024-01-02T15:04:05"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for _, s := range tt.valid {
+				if !tt.fn(s) {
+					t.Errorf("%q should be valid", s)
+				}
+			}
+			for _, s := range tt.invalid {
+				if tt.fn(s) {
+					t.Errorf("%q should be invalid", s)
+				}
+			}
+		})
+	}
+}